@@ -0,0 +1,32 @@
+// Decodes the server's compact map encoding (game.CompactMap, see PROTOCOL.md)
+// back into the mapData shape the scenes expect
+
+const TILE_SIZE = 16;
+
+export const compactMapSupported = typeof DecompressionStream !== 'undefined';
+
+export async function decodeCompactMap(compact: any): Promise<any> {
+  const packed = Uint8Array.from(atob(compact.tiles), c => c.charCodeAt(0));
+  const stream = new Blob([packed]).stream().pipeThrough(new DecompressionStream('gzip'));
+  const tiles = new Uint8Array(await new Response(stream).arrayBuffer());
+
+  const cols = compact.width / TILE_SIZE;
+  const rows = compact.height / TILE_SIZE;
+  const terrain: number[][] = [];
+  const mapObjects: any[] = [];
+
+  for (let y = 0; y < rows; y++) {
+    const row: number[] = [];
+    for (let x = 0; x < cols; x++) {
+      const i = y * cols + x;
+      row.push(tiles[i] - 1); // Stored plus one so outside tiles are 0
+      const id = tiles[cols * rows + i];
+      if (id !== 0) {
+        mapObjects.push({ id: String(id), x, y, isPicked: false });
+      }
+    }
+    terrain.push(row);
+  }
+
+  return { width: compact.width, height: compact.height, seed: compact.seed, terrain, mapObjects, spawnZones: compact.spawnZones ?? [] };
+}
@@ -1,11 +1,94 @@
 import Sockette from "sockette";
 import { getWebSocketUrl } from '../config/websocket';
+import { compactMapSupported, decodeCompactMap } from './compactMap';
 
 let ws: Sockette | null = null;
 let isOpen = false;
 const messageQueue: Array<{ type: string; content?: any }> = [];
 const subscriptions: Record<string, Array<Function>> = {};
 
+// Must match the server's ProtocolVersion, see PROTOCOL.md
+const PROTOCOL_VERSION = 2;
+const FEATURES = ['delta', 'compression', ...(compactMapSupported ? ['compactMap'] : [])];
+
+// Compact maps decode asynchronously, so messages are handled in a chain
+// to keep them in the order they arrived
+let inbound: Promise<void> = Promise.resolve();
+
+// Recent game states by snapshot number, used as baselines for gameDelta
+const SNAPSHOT_HISTORY = 128;
+const snapshots = new Map<number, any>();
+
+function storeSnapshot(seq: number, gameState: any) {
+  snapshots.set(seq, gameState);
+  for (const key of snapshots.keys()) {
+    if (key <= seq - SNAPSHOT_HISTORY) {
+      snapshots.delete(key);
+    }
+  }
+}
+
+// Rebuild the full game state from a delta against an acked snapshot
+function applyDelta(message: any): any | null {
+  const base = snapshots.get(message.baseline);
+  if (!base) {
+    return null;
+  }
+
+  const players: Record<string, any> = { ...base.players };
+  for (const [id, fields] of Object.entries(message.players || {})) {
+    players[id] = { ...players[id], ...(fields as any) };
+  }
+  for (const id of message.removed || []) {
+    delete players[id];
+  }
+
+  return {
+    players,
+    gamePhase: message.gamePhase ?? base.gamePhase,
+    timer: message.timer ?? base.timer,
+    round: message.round ?? base.round,
+    score: message.score ?? base.score,
+    totalScore: message.totalScore ?? base.totalScore,
+  };
+}
+
+async function handleMessage(e: MessageEvent) {
+  if (!e.data) {
+    return;
+  }
+  let message = JSON.parse(e.data);
+
+  // Listeners always see mapData, however the server sent it
+  if (message.compactMap) {
+    message.mapData = await decodeCompactMap(message.compactMap);
+    delete message.compactMap;
+  }
+
+  // Deltas are expanded back into a full gameUpdate for listeners
+  if (message.type === 'gameDelta') {
+    const gameState = applyDelta(message);
+    if (!gameState) {
+      return; // Baseline is gone - wait for the next keyframe
+    }
+    message = { type: 'gameUpdate', gameState, snapshot: message.snapshot, timestamp: message.timestamp };
+  }
+  if (message.type === 'gameUpdate' && message.snapshot) {
+    storeSnapshot(message.snapshot, message.gameState);
+    ws?.json({ type: 'snapshotAck', content: { snapshot: message.snapshot } });
+  }
+
+  if (message.type === 'error' && message.code === 'unsupported_version') {
+    console.error(message.error);
+  }
+
+  if(subscriptions[message.type] && subscriptions[message.type].length > 0) {
+    for(let cb of subscriptions[message.type]) {
+      cb(message);
+    }
+  }
+}
+
 export function useWS() {
  
   function init() {
@@ -19,6 +102,8 @@ export function useWS() {
       maxAttempts: 10,
       onopen: e => {
         isOpen = true;
+        // The server answers anything sent before hello with an error
+        ws?.json({ type: 'hello', content: { protocolVersion: PROTOCOL_VERSION, features: FEATURES } });
         // Flush any queued messages
         while (messageQueue.length > 0) {
           const msg = messageQueue.shift();
@@ -28,14 +113,7 @@ export function useWS() {
         }
       },
       onmessage: e => {
-        if(e.data) {
-          const message = JSON.parse(e.data);
-          if(subscriptions[message.type] && subscriptions[message.type].length > 0) {
-            for(let cb of subscriptions[message.type]) {
-              cb(message);
-            }
-          }
-        }
+        inbound = inbound.then(() => handleMessage(e)).catch(err => console.error('Error handling message:', err));
       },
       onreconnect: e => {
         isOpen = false; // Connection is reconnecting, not open yet
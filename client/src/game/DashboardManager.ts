@@ -138,7 +138,7 @@ export default class DashboardManager {
   handleBulletDestroy(data: any): void {
     const scene = this.getScene();
     if (scene && this.sceneReady) {
-      scene.destroyBullet(data.bulletId);
+      scene.destroyBullet(`${data.ownerId}:${data.bulletId}`);
     }
   }
 
@@ -166,6 +166,14 @@ export default class DashboardManager {
     }
   }
 
+  // Handle a new map between rounds
+  handleMapChanged(mapData: any): void {
+    const scene = this.getScene();
+    if (scene && this.sceneReady) {
+      scene.redrawMap(mapData);
+    }
+  }
+
   // Play countdown and start game
   playCountdownAndStart(onComplete: () => void): void {
     const scene = this.getScene();
@@ -0,0 +1,15 @@
+// Map size in tiles, read from the mapData the server sent on join.
+// Maps are sized per room, so nothing here may assume 200x200.
+const DEFAULT_MAP_TILES = 200;
+
+export function storedMapSize(tileSize: number): { mapWidth: number; mapHeight: number } {
+  try {
+    const mapData = JSON.parse(sessionStorage.getItem('mapData') || 'null');
+    if (mapData && mapData.width && mapData.height) {
+      return { mapWidth: mapData.width / tileSize, mapHeight: mapData.height / tileSize };
+    }
+  } catch (e) {
+    console.error('Failed to parse map size:', e);
+  }
+  return { mapWidth: DEFAULT_MAP_TILES, mapHeight: DEFAULT_MAP_TILES };
+}
@@ -1,5 +1,6 @@
 import Phaser from 'phaser';
 import type { Player, GameState } from '../../types';
+import { storedMapSize } from '../mapSize';
 
 interface SceneData {
   ws: any;
@@ -24,8 +25,8 @@ export default class DashboardScene extends Phaser.Scene {
   private tilemap!: Phaser.Tilemaps.Tilemap;
   private terrainLayer!: Phaser.Tilemaps.TilemapLayer;
   private objectsLayer!: Phaser.Tilemaps.TilemapLayer;
-  private worldWidth: number = 3200;  // 200 tiles * 16 pixels
-  private worldHeight: number = 3200; // 200 tiles * 16 pixels
+  private worldWidth: number = 3200;  // Set from the server's map in create()
+  private worldHeight: number = 3200;
   private currentZoom: number = 3;
   private zoomOutFactor: number = 2; // How much to zoom out (2x means half the zoom)
   private bgMusic: Phaser.Sound.BaseSound | null = null;
@@ -96,10 +97,11 @@ export default class DashboardScene extends Phaser.Scene {
   create(): void {
     console.log('DashboardScene create() called');
 
-    // Define tilemap dimensions - always 200x200 to match server
+    // Tilemap dimensions come from the map the server sent
     const tileSize = 16;
-    const mapWidth = 200;  // Fixed to match server map size
-    const mapHeight = 200; // Fixed to match server map size
+    const { mapWidth, mapHeight } = storedMapSize(tileSize);
+    this.worldWidth = mapWidth * tileSize;
+    this.worldHeight = mapHeight * tileSize;
 
     // Set world bounds to match the full map
     this.physics.world.setBounds(0, 0, mapWidth * tileSize, mapHeight * tileSize);
@@ -110,7 +112,7 @@ export default class DashboardScene extends Phaser.Scene {
     // Create player animations
     this.createPlayerAnimations();
 
-    // Setup camera - Use the actual map size (200 tiles * 16 pixels)
+    // Setup camera - Use the actual map size (tiles * 16 pixels)
     this.cameras.main.setBounds(0, 0, mapWidth * tileSize, mapHeight * tileSize);
     this.cameras.main.setZoom(this.currentZoom);
     this.cameras.main.setRoundPixels(true);
@@ -204,9 +206,8 @@ export default class DashboardScene extends Phaser.Scene {
     // Use server terrain data or fallback to random generation
     if (terrainData && terrainData.length > 0) {
       // Use server-provided terrain
-      const serverMapSize = terrainData.length;
-      for (let y = 0; y < Math.min(mapHeight, serverMapSize); y++) {
-        for (let x = 0; x < Math.min(mapWidth, serverMapSize); x++) {
+      for (let y = 0; y < Math.min(mapHeight, terrainData.length); y++) {
+        for (let x = 0; x < Math.min(mapWidth, terrainData[y].length); x++) {
           const tileIndex = terrainData[y][x] || 0;
           this.terrainLayer.putTileAt(tileIndex, x, y);
         }
@@ -258,6 +259,33 @@ export default class DashboardScene extends Phaser.Scene {
     this.objectsLayer.setCollisionBetween(7, 9);
   }
 
+  // Repaint the map after the server generated a new one between rounds.
+  // Maps keep their size across rounds, so the existing layers are reused.
+  public redrawMap(mapData: any): void {
+    if (!this.terrainLayer || !this.objectsLayer || !mapData?.terrain) return;
+
+    const width = this.terrainLayer.layer.width;
+    const height = this.terrainLayer.layer.height;
+    for (let y = 0; y < Math.min(height, mapData.terrain.length); y++) {
+      for (let x = 0; x < Math.min(width, mapData.terrain[y].length); x++) {
+        this.terrainLayer.putTileAt(mapData.terrain[y][x] || 0, x, y);
+      }
+    }
+
+    // Only walls and cacti (7-9) are drawn, as in createTilemap
+    this.objectsLayer.forEachTile(tile => {
+      if (tile.index !== -1) {
+        this.objectsLayer.removeTileAt(tile.x, tile.y);
+      }
+    });
+    for (const obj of mapData.mapObjects || []) {
+      const objId = parseInt(obj.id);
+      if (objId >= 7 && objId <= 9 && obj.x >= 0 && obj.x < width && obj.y >= 0 && obj.y < height) {
+        this.objectsLayer.putTileAt(objId, obj.x, obj.y);
+      }
+    }
+  }
+
   public updateGameState(gameState: GameState): void {
     if (!gameState || !gameState.players) return;
 
@@ -640,8 +668,9 @@ export default class DashboardScene extends Phaser.Scene {
     // Add tint effect to bullet
     bullet.setTint(0xffff88); // Yellowish tint for visibility
 
-    // Store bullet
-    this.bullets.set(bulletId, bullet);
+    // Bullet IDs are chosen by each client, so track them per owner
+    const key = `${ownerId}:${bulletId}`;
+    this.bullets.set(key, bullet);
 
     // Calculate end position using angle
     const distance = 1500;
@@ -667,12 +696,12 @@ export default class DashboardScene extends Phaser.Scene {
           if (tile && tile.collides) {
             // Bullet hit a wall
             tween.stop();
-            this.destroyBullet(bulletId);
+            this.destroyBullet(key);
           }
         }
       },
       onComplete: () => {
-        this.destroyBullet(bulletId);
+        this.destroyBullet(key);
       }
     });
   }
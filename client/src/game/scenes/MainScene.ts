@@ -1,4 +1,5 @@
 import Phaser from 'phaser';
+import { storedMapSize } from '../mapSize';
 
 interface SceneData {
   roomCode: string;
@@ -54,8 +55,8 @@ export default class MainScene extends Phaser.Scene {
   private tilemap!: Phaser.Tilemaps.Tilemap;
   private terrainLayer!: Phaser.Tilemaps.TilemapLayer;
   private objectsLayer!: Phaser.Tilemaps.TilemapLayer;
-  private worldWidth: number = 3200;  // 200 tiles * 16 pixels
-  private worldHeight: number = 3200; // 200 tiles * 16 pixels
+  private worldWidth: number = 3200;  // Set from the server's map in create()
+  private worldHeight: number = 3200;
   private currentZoom: number = 3;
   private zoomKeys!: {
     plus: Phaser.Input.Keyboard.Key;
@@ -88,6 +89,8 @@ export default class MainScene extends Phaser.Scene {
   private isInAmmoQuiz: boolean = false;
   private gameStarted: boolean = false; // Track if game has started
   private isGameEnded: boolean = false; // Track if game has ended
+  private isPaused: boolean = false; // Dashboard has paused the game
+  private gameOverOverlay: Phaser.GameObjects.Rectangle | null = null;
 
   // Kill tracking
   private killCount: number = 0;
@@ -148,10 +151,11 @@ export default class MainScene extends Phaser.Scene {
       }
     }
 
-    // Define tilemap dimensions - always 200x200 to match server
+    // Tilemap dimensions come from the map the server sent
     const tileSize = 16;
-    const mapWidth = 200;  // Fixed to match server map size
-    const mapHeight = 200; // Fixed to match server map size
+    const { mapWidth, mapHeight } = storedMapSize(tileSize);
+    this.worldWidth = mapWidth * tileSize;
+    this.worldHeight = mapHeight * tileSize;
 
     // console.log(`Creating tilemap for ${this.worldWidth}x${this.worldHeight} world (${mapWidth}x${mapHeight} tiles)`);
 
@@ -185,7 +189,7 @@ export default class MainScene extends Phaser.Scene {
     }
 
     // Setup camera to follow player in the larger world
-    // Use the actual map size (200 tiles * 16 pixels)
+    // Use the actual map size (tiles * 16 pixels)
     this.cameras.main.setBounds(0, 0, mapWidth * tileSize, mapHeight * tileSize);
     this.cameras.main.startFollow(this.localPlayer, true, 1, 1); // Instant camera follow (no smoothing for performance)
     this.cameras.main.setZoom(this.currentZoom); // Zoom in closer to the player
@@ -322,8 +326,6 @@ export default class MainScene extends Phaser.Scene {
         const mapData = JSON.parse(mapDataStr);
         if (mapData && mapData.terrain) {
           terrainData = mapData.terrain;
-          // Server sends dimensions in pixels, but we're using fixed 200x200 tiles
-          // Don't update world dimensions since we're using fixed map size
           // Get map objects (walls, cactus, chests, loot)
           if (mapData.mapObjects) {
             mapObjects = mapData.mapObjects;
@@ -338,9 +340,8 @@ export default class MainScene extends Phaser.Scene {
     // Use server terrain data or fallback to random generation
     if (terrainData && terrainData.length > 0) {
       // Use server-provided terrain
-      const serverMapSize = terrainData.length;
-      for (let y = 0; y < Math.min(mapHeight, serverMapSize); y++) {
-        for (let x = 0; x < Math.min(mapWidth, serverMapSize); x++) {
+      for (let y = 0; y < Math.min(mapHeight, terrainData.length); y++) {
+        for (let x = 0; x < Math.min(mapWidth, terrainData[y].length); x++) {
           const tileIndex = terrainData[y][x] || 0;
           this.terrainLayer.putTileAt(tileIndex, x, y);
         }
@@ -429,6 +430,33 @@ export default class MainScene extends Phaser.Scene {
     // console.log(`Tilemap created: ${mapWidth}x${mapHeight} tiles (${tileSize}px each) = ${mapWidth * tileSize}x${mapHeight * tileSize}px total`);
   }
 
+  // Repaint the map after the server generated a new one between rounds.
+  // Maps keep their size across rounds, so the existing layers are reused.
+  redrawMap(mapData: any): void {
+    if (!this.terrainLayer || !this.objectsLayer || !mapData?.terrain) return;
+
+    const width = this.terrainLayer.layer.width;
+    const height = this.terrainLayer.layer.height;
+    for (let y = 0; y < Math.min(height, mapData.terrain.length); y++) {
+      for (let x = 0; x < Math.min(width, mapData.terrain[y].length); x++) {
+        this.terrainLayer.putTileAt(mapData.terrain[y][x] || 0, x, y);
+      }
+    }
+
+    // Only walls and cacti (7-9) are drawn, as in createTilemap
+    this.objectsLayer.forEachTile(tile => {
+      if (tile.index !== -1) {
+        this.objectsLayer.removeTileAt(tile.x, tile.y);
+      }
+    });
+    for (const obj of mapData.mapObjects || []) {
+      const objId = parseInt(obj.id);
+      if (objId >= 7 && objId <= 9 && obj.x >= 0 && obj.x < width && obj.y >= 0 && obj.y < height) {
+        this.objectsLayer.putTileAt(objId, obj.x, obj.y).setCollision(true);
+      }
+    }
+  }
+
   createLocalPlayer(): void {
     // Create the player sprite at the center of the world
     const startX = this.worldWidth / 2;
@@ -518,6 +546,12 @@ export default class MainScene extends Phaser.Scene {
           // console.log('Received initialState with players:', Object.keys(data.gameState?.players || {}));
           this.updateGameState(data.gameState);
 
+          // Rejoining while dead - the server still has us waiting to respawn
+          const self = data.gameState?.players?.[this.playerId];
+          if (self && self.health <= 0 && !this.isDead) {
+            this.handleRemotePlayerDeath({ playerId: this.playerId });
+          }
+
           // Check if game has already started
           if (data.gameState?.gamePhase === 'playing') {
             this.gameStarted = true;
@@ -544,6 +578,43 @@ export default class MainScene extends Phaser.Scene {
         }
       });
 
+      // The server generated a new map for the next round
+      this.ws.on('mapChanged', (data: any) => {
+        if (data.mapData) {
+          sessionStorage.setItem('mapData', JSON.stringify(data.mapData));
+          if (this.scene.isActive()) {
+            this.redrawMap(data.mapData);
+          }
+        }
+      });
+
+      // A new round is starting with everyone healed at fresh spawns
+      this.ws.on('roundReset', (data: any) => {
+        if (this.scene.isActive()) {
+          this.handleRoundReset(data);
+        }
+      });
+
+      // The dashboard paused or resumed the match
+      this.ws.on('gamePaused', () => {
+        if (this.scene.isActive()) {
+          this.setPaused(true);
+        }
+      });
+
+      this.ws.on('gameResumed', () => {
+        if (this.scene.isActive()) {
+          this.setPaused(false);
+        }
+      });
+
+      // The server rejected part of a move - snap back to where it has us
+      this.ws.on('positionCorrection', (data: any) => {
+        if (this.scene.isActive() && this.localPlayer) {
+          this.localPlayer.setPosition(data.x, data.y);
+        }
+      });
+
       // Listen for bullet spawns from other players
       this.ws.on('bulletSpawn', (data: any) => {
         if (this.scene.isActive() && data.ownerId !== this.playerId) {
@@ -554,7 +625,7 @@ export default class MainScene extends Phaser.Scene {
       // Listen for bullet destroys
       this.ws.on('bulletDestroy', (data: any) => {
         if (this.scene.isActive()) {
-          this.removeOtherPlayerBullet(data.bulletId);
+          this.removeOtherPlayerBullet(`${data.ownerId}:${data.bulletId}`);
         }
       });
 
@@ -603,6 +674,12 @@ export default class MainScene extends Phaser.Scene {
       }
     }
 
+    // Joined or caught up while paused
+    if (gameState.gamePhase && (gameState.gamePhase === 'paused') !== this.isPaused) {
+      this.gameStarted = true;
+      this.setPaused(gameState.gamePhase === 'paused');
+    }
+
     // Check if game has ended
     if (gameState.gamePhase === 'ended' && !this.isGameEnded) {
       this.handleGameEnded({ scores: gameState.scores || {} });
@@ -900,6 +977,9 @@ export default class MainScene extends Phaser.Scene {
     // Don't process input if dead and showing quiz
     if (this.isDead) return;
 
+    // Nobody moves or shoots while paused
+    if (this.isPaused) return;
+
     // Movement is allowed even during waiting state
     // Handle player movement
     let velocityX = 0;
@@ -1102,8 +1182,7 @@ export default class MainScene extends Phaser.Scene {
         direction: direction,
         gunRotation: gunRotation,
         gunFlipped: gunFlipped,
-        currentGun: this.currentGun,
-        isProtected: this.isDead || this.isInAmmoQuiz  // Add protection state
+        currentGun: this.currentGun
       });
     }
   }
@@ -1338,15 +1417,7 @@ export default class MainScene extends Phaser.Scene {
     // Destroy the bullet
     this.destroyBullet(bullet);
 
-    // If target died, send death message and handle kill
-    if (health <= 0) {
-      this.ws.send('playerDeath', {
-        playerId: targetPlayerId
-      });
-
-      // Handle kill tracking and announcements
-      this.handleKill();
-    }
+    // The server decides deaths and announces them with playerDeath
   }
 
   flashWhite(sprite: PlayerSprite): void {
@@ -1400,11 +1471,12 @@ export default class MainScene extends Phaser.Scene {
     // Add tint effect to bullet
     bullet.setTint(0xffff88); // Yellowish tint for visibility
 
-    // Store bullet ID for tracking
-    bullet.bulletId = data.bulletId;
+    // Bullet IDs are chosen by each client, so track them per owner
+    const key = `${data.ownerId}:${data.bulletId}`;
+    bullet.bulletId = key;
 
     // Store in tracking map
-    this.otherPlayerBullets.set(data.bulletId, bullet);
+    this.otherPlayerBullets.set(key, bullet);
 
     // Create tween for bullet movement
     const distance = 1500;
@@ -1428,12 +1500,12 @@ export default class MainScene extends Phaser.Scene {
           if (tile && tile.collides) {
             // Bullet hit a wall
             tween.stop();
-            this.removeOtherPlayerBullet(data.bulletId);
+            this.removeOtherPlayerBullet(key);
           }
         }
       },
       onComplete: () => {
-        this.removeOtherPlayerBullet(data.bulletId);
+        this.removeOtherPlayerBullet(key);
       }
     });
   }
@@ -1469,6 +1541,11 @@ export default class MainScene extends Phaser.Scene {
   }
 
   handleRemotePlayerDeath(data: any): void {
+    // Kills are counted from the server's announcement
+    if (data.killerId === this.playerId && data.playerId !== this.playerId) {
+      this.handleKill();
+    }
+
     if (data.playerId === this.playerId) {
       // Local player died - show quiz
       this.localPlayer.setVisible(false);
@@ -1532,6 +1609,17 @@ export default class MainScene extends Phaser.Scene {
     }
   }
 
+  // Freeze local input while the dashboard has the game paused
+  setPaused(paused: boolean): void {
+    this.isPaused = paused;
+    this.localPlayer?.setVelocity(0, 0);
+
+    const uiScene = this.scene.get('UIScene') as any;
+    if (uiScene && uiScene.setPausedVisible) {
+      uiScene.setPausedVisible(paused);
+    }
+  }
+
   handleKill(): void {
     const currentTime = this.time.now;
 
@@ -1541,9 +1629,6 @@ export default class MainScene extends Phaser.Scene {
     // Update score UI
     this.updateScoreUI();
 
-    // Send kill update to server for score calculation
-    this.sendKillUpdate();
-
     // Check if it's a streak (within 5 seconds of last kill)
     if (currentTime - this.lastKillTime < 5000) {
       this.killStreak++;
@@ -1624,32 +1709,13 @@ export default class MainScene extends Phaser.Scene {
     return '';
   }
 
-  // Send correct answers count to server for score calculation
-  sendCorrectAnswers(count: number): void {
-    this.correctAnswers += count;
-    this.questionsAttempted += 3; // Each quiz has exactly 3 questions
+  // Show the quiz totals the server graded
+  updateQuizScore(correctAnswers: number, questionsAttempted: number): void {
+    this.correctAnswers = correctAnswers;
+    this.questionsAttempted = questionsAttempted;
 
     // Update score UI
     this.updateScoreUI();
-
-    if (this.ws && this.ws.send) {
-      this.ws.send('updateScore', {
-        correctAnswers: this.correctAnswers,
-        questionsAttempted: this.questionsAttempted,
-        kills: this.totalKills
-      });
-    }
-  }
-
-  // Send kill to server for score calculation
-  sendKillUpdate(): void {
-    if (this.ws && this.ws.send) {
-      this.ws.send('updateScore', {
-        correctAnswers: this.correctAnswers,
-        questionsAttempted: this.questionsAttempted,
-        kills: this.totalKills
-      });
-    }
   }
 
   handleRemotePlayerRespawn(data: any): void {
@@ -1691,6 +1757,39 @@ export default class MainScene extends Phaser.Scene {
     }
   }
 
+  handleRoundReset(data: any): void {
+    // Clear the game over screen and wait for the countdown to finish
+    this.isGameEnded = false;
+    this.gameStarted = false;
+    this.isDead = false;
+    this.gameOverOverlay?.destroy();
+    this.gameOverOverlay = null;
+
+    const uiScene = this.scene.get('UIScene') as any;
+    if (uiScene && uiScene.hideGameOver) {
+      uiScene.hideGameOver();
+    }
+    if (uiScene && uiScene.setWaitingVisible) {
+      uiScene.setWaitingVisible(true);
+    }
+
+    // Round scores start again from zero
+    this.totalKills = 0;
+    this.killStreak = 0;
+    this.correctAnswers = 0;
+    this.questionsAttempted = 0;
+    this.updateScoreUI();
+
+    this.ammo[0] = 6;   // Pistol starting ammo
+    this.ammo[1] = 4;   // Shotgun starting ammo
+    this.ammo[2] = 15;  // Uzi starting ammo
+    this.updateAmmoUI();
+
+    for (const [playerId, player] of Object.entries(data.players || {}) as [string, any][]) {
+      this.handleRemotePlayerRespawn({ playerId, x: player.x, y: player.y });
+    }
+  }
+
   handleGameEnded(data: any): void {
     // Prevent multiple calls
     if (this.isGameEnded) return;
@@ -1723,6 +1822,7 @@ export default class MainScene extends Phaser.Scene {
     );
     overlay.setScrollFactor(0);
     overlay.setDepth(50);
+    this.gameOverOverlay = overlay;
 
     // Fade in the overlay
     overlay.setAlpha(0);
@@ -1768,12 +1868,8 @@ export default class MainScene extends Phaser.Scene {
     this.localPlayer.nameText?.setAlpha(0.5);
     this.localPlayer.gunSprite?.setAlpha(0.5);
 
-    // Force send position update to inform server about protection status
-    const animation = this.localPlayer.body?.velocity.length() ?? 0 > 0 ? 'running' : 'idle';
-    const direction = this.localPlayer.flipX ? 'left' : 'right';
-    this.sendPositionUpdate(this.localPlayer.x, this.localPlayer.y, animation, direction);
-
-    // Trigger the ammo quiz via global function
+    // Trigger the ammo quiz via global function - the server protects the
+    // player while they answer, since it knows they're out of ammo
     if ((window as any).showAmmoQuiz) {
       (window as any).showAmmoQuiz();
     }
@@ -1792,11 +1888,6 @@ export default class MainScene extends Phaser.Scene {
     this.localPlayer.nameText?.setAlpha(1);
     this.localPlayer.gunSprite?.setAlpha(1);
 
-    // Force send position update to inform server that protection is over
-    const animation = this.localPlayer.body?.velocity.length() ?? 0 > 0 ? 'running' : 'idle';
-    const direction = this.localPlayer.flipX ? 'left' : 'right';
-    this.sendPositionUpdate(this.localPlayer.x, this.localPlayer.y, animation, direction);
-
     this.updateAmmoUI();
   }
 }
\ No newline at end of file
@@ -17,6 +17,7 @@ export default class UIScene extends Phaser.Scene {
   // Controls bar elements (for resize handling)
   private controlsBg!: Phaser.GameObjects.Rectangle;
   private controlsText!: Phaser.GameObjects.Text;
+  private gameOverObjects: Phaser.GameObjects.GameObject[] = [];
 
   // Timer value
   private gameTimer: number = 300;
@@ -363,6 +364,23 @@ export default class UIScene extends Phaser.Scene {
     }
   }
 
+  // Reuse the waiting banner to show the game is paused
+  setPausedVisible(paused: boolean): void {
+    if (this.waitingText) {
+      this.waitingText.setText(paused ? '⏸ Game paused' : '⚠ Waiting for game • Shooting disabled');
+    }
+    this.setWaitingVisible(paused);
+  }
+
+  // Remove the game over screen when a new round starts
+  hideGameOver(): void {
+    for (const object of this.gameOverObjects) {
+      this.tweens.killTweensOf(object);
+      object.destroy();
+    }
+    this.gameOverObjects = [];
+  }
+
   // Show game over screen
   showGameOver(kills: number, correctAnswers: number): void {
     const screenWidth = this.cameras.main.width;
@@ -451,6 +469,8 @@ export default class UIScene extends Phaser.Scene {
     instructionText.setScrollFactor(0);
     instructionText.setDepth(2002);
 
+    this.gameOverObjects = [gameOverBg, containerBg, gameOverTitle, killsRow, answersRow, scoreText, instructionText];
+
     // Pulse animation on title
     this.tweens.add({
       targets: gameOverTitle,
@@ -4,7 +4,7 @@ import type { Player, GameState } from '../types'
 
 export const useGameStore = defineStore('game', () => {
   const players = ref<Record<string, Player>>({})
-  const gamePhase = ref<'waiting' | 'playing' | 'ended'>('waiting')
+  const gamePhase = ref<'lobby' | 'countdown' | 'playing' | 'paused' | 'ended' | 'results'>('lobby')
   const timer = ref(0)
   const score = ref<Record<string, number>>({})
 
@@ -38,7 +38,7 @@ export const useGameStore = defineStore('game', () => {
 
   function reset() {
     players.value = {}
-    gamePhase.value = 'waiting'
+    gamePhase.value = 'lobby'
     timer.value = 0
     score.value = {}
   }
@@ -16,7 +16,7 @@ export interface Player {
 
 export interface GameState {
   players: Record<string, Player>
-  gamePhase: 'waiting' | 'playing' | 'ended'
+  gamePhase: 'lobby' | 'countdown' | 'playing' | 'paused' | 'ended' | 'results'
   timer: number
   score: Record<string, number>
 }
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"math"
+
+	"github.com/AmeenAhmed/hackathon/game"
+	"github.com/AmeenAhmed/hackathon/wire"
+)
+
+// aoiCellSize is the edge length in pixels of a spatial grid cell.
+// Roughly a third of the default view radius keeps queries to a few cells.
+const aoiCellSize = 256
+
+// gridCell identifies a cell of the spatial grid
+type gridCell struct {
+	X, Y int
+}
+
+// gridEntry is a player position stored in the grid
+type gridEntry struct {
+	ID   string
+	X, Y float64
+}
+
+// spatialGrid buckets player positions into fixed-size cells over map pixel
+// space so range queries only look at nearby players. A grid is built once
+// per tick and never modified afterwards, so it can be shared freely.
+type spatialGrid struct {
+	cells map[gridCell][]gridEntry
+}
+
+// newSpatialGrid indexes the positions of every player in the snapshot
+func newSpatialGrid(players map[string]Player) *spatialGrid {
+	g := &spatialGrid{cells: make(map[gridCell][]gridEntry)}
+	for id, player := range players {
+		cell := cellAt(player.X, player.Y)
+		g.cells[cell] = append(g.cells[cell], gridEntry{ID: id, X: player.X, Y: player.Y})
+	}
+	return g
+}
+
+// cellAt returns the cell containing a pixel position
+func cellAt(x, y float64) gridCell {
+	return gridCell{
+		X: int(math.Floor(x / aoiCellSize)),
+		Y: int(math.Floor(y / aoiCellSize)),
+	}
+}
+
+// query calls visit for every entry in the cells overlapping the box
+func (g *spatialGrid) query(minX, minY, maxX, maxY float64, visit func(gridEntry)) {
+	lo, hi := cellAt(minX, minY), cellAt(maxX, maxY)
+	for cy := lo.Y; cy <= hi.Y; cy++ {
+		for cx := lo.X; cx <= hi.X; cx++ {
+			for _, entry := range g.cells[gridCell{cx, cy}] {
+				visit(entry)
+			}
+		}
+	}
+}
+
+// within returns the IDs of players within radius of (x, y)
+func (g *spatialGrid) within(x, y, radius float64) map[string]bool {
+	ids := make(map[string]bool)
+	g.query(x-radius, y-radius, x+radius, y+radius, func(e gridEntry) {
+		if math.Hypot(e.X-x, e.Y-y) <= radius {
+			ids[e.ID] = true
+		}
+	})
+	return ids
+}
+
+// alongSegment returns the IDs of players within radius of any point on
+// the segment from (x1, y1) to (x2, y2)
+func (g *spatialGrid) alongSegment(x1, y1, x2, y2, radius float64) map[string]bool {
+	ids := make(map[string]bool)
+	g.query(math.Min(x1, x2)-radius, math.Min(y1, y2)-radius,
+		math.Max(x1, x2)+radius, math.Max(y1, y2)+radius, func(e gridEntry) {
+			if game.SegmentHitsCircle(x1, y1, x2, y2, e.X, e.Y, radius) {
+				ids[e.ID] = true
+			}
+		})
+	return ids
+}
+
+// visibleSet is the players a client was sent in one snapshot
+type visibleSet struct {
+	seq uint64
+	ids map[string]bool
+}
+
+// filtered returns a copy of the snapshot holding only the given players.
+// Scores and the match clock aren't positional and are kept whole.
+func (s *snapshot) filtered(ids map[string]bool) *snapshot {
+	view := *s
+	view.Players = make(map[string]Player, len(ids))
+	for id := range ids {
+		if player, exists := s.Players[id]; exists {
+			view.Players[id] = player
+		}
+	}
+	return &view
+}
+
+// viewFor returns the players a client may see this tick, or nil if the
+// client sees everything. Called from the ticker, or with the lock held.
+func (r *Room) viewFor(client *Client, snap *snapshot, grid *spatialGrid) map[string]bool {
+	radius := r.Settings.ViewRadius
+	if client.IsDashboard || radius <= 0 {
+		return nil
+	}
+
+	self, exists := snap.Players[client.ID]
+	if !exists {
+		return map[string]bool{}
+	}
+	ids := grid.within(self.X, self.Y, radius)
+	ids[client.ID] = true
+	return ids
+}
+
+// visibleState is the game state limited to the players the client may
+// see. Players are copied so it can be marshaled after the lock is
+// released. Called with the lock held.
+func (r *Room) visibleState(client *Client) GameState {
+	players := make(map[string]Player, len(r.GameState.Players))
+	for id, player := range r.GameState.Players {
+		players[id] = *player
+	}
+	ids := r.viewFor(client, &snapshot{Players: players}, newSpatialGrid(players))
+
+	state := r.GameState
+	state.Players = make(map[string]*Player, len(players))
+	for id, player := range players {
+		if ids == nil || ids[id] {
+			player := player
+			state.Players[id] = &player
+		}
+	}
+	return state
+}
+
+// visibleAt returns the players the client was sent in snapshot seq,
+// or nil if that has been forgotten. Ticker only.
+func (c *Client) visibleAt(seq uint64) map[string]bool {
+	set := c.visible[seq%snapshotHistory]
+	if set.seq != seq {
+		return nil
+	}
+	return set.ids
+}
+
+// broadcastNear sends a message about something at (x, y) to the dashboard
+// and every player whose view covers it, plus any players listed in always
+func (r *Room) broadcastNear(x, y float64, message interface{}, always ...string) {
+	r.broadcastVisible(message, func(grid *spatialGrid, radius float64) map[string]bool {
+		return grid.within(x, y, radius)
+	}, always, "")
+}
+
+// broadcastAlong sends a message about something travelling from (x1, y1)
+// to (x2, y2) to the dashboard and every player who could see any part of
+// the path, except the player it came from
+func (r *Room) broadcastAlong(x1, y1, x2, y2 float64, senderID string, message interface{}) {
+	r.broadcastVisible(message, func(grid *spatialGrid, radius float64) map[string]bool {
+		return grid.alongSegment(x1, y1, x2, y2, radius)
+	}, nil, senderID)
+}
+
+// broadcastVisible sends message to the dashboard and the players chosen by
+// audience from the latest grid, skipping exclude. Without a grid or a view
+// radius, every player is a recipient.
+func (r *Room) broadcastVisible(message interface{}, audience func(*spatialGrid, float64) map[string]bool, always []string, exclude string) {
+	encoded, err := json.Marshal(message)
+	if err != nil {
+		log.Printf("Error marshaling message: %v", err)
+		return
+	}
+	data := wire.NewMessage(encoded)
+
+	var ids map[string]bool
+	if grid := r.grid.Load(); grid != nil && r.Settings.ViewRadius > 0 {
+		ids = audience(grid, r.Settings.ViewRadius)
+		for _, id := range always {
+			ids[id] = true
+		}
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if r.Dashboard != nil {
+		select {
+		case r.Dashboard.Send <- data:
+		default:
+			// Dashboard's send channel is full, skip
+		}
+	}
+
+	for id, client := range r.Players {
+		if id == exclude || (ids != nil && !ids[id]) {
+			continue
+		}
+		select {
+		case client.Send <- data:
+		default:
+			// Client's send channel is full, skip
+		}
+	}
+}
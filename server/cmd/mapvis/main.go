@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"image"
 	"image/draw"
@@ -25,6 +26,16 @@ type Spritesheet struct {
 }
 
 func main() {
+	seed := flag.Int64("seed", -1, "render only the map with this seed")
+	presetName := flag.String("preset", "full", "generator preset: small, medium or full")
+	flag.Parse()
+
+	cfg, exists := game.Preset(*presetName)
+	if !exists {
+		fmt.Printf("Unknown preset %q\n", *presetName)
+		return
+	}
+
 	// Load spritesheet
 	spritesheet, err := loadSpritesheet("/Users/anask/code/personal/hackathon/assets/terrain.png")
 	if err != nil {
@@ -32,11 +43,19 @@ func main() {
 		return
 	}
 	fmt.Printf("Loaded spritesheet with %d tiles\n", len(spritesheet.tiles))
-	fmt.Printf("Map size: %d x %d tiles\n\n", game.MapSize, game.MapSize)
+	fmt.Printf("Map size: %d x %d tiles\n\n", cfg.Width, cfg.Height)
+
+	if *seed >= 0 {
+		fmt.Printf("Generating map with seed %d...\n", *seed)
+		renderMap(1, cfg, *seed, spritesheet)
+		fmt.Println("Done!")
+		return
+	}
 
 	for i := 1; i <= 3; i++ {
-		fmt.Printf("Generating map %d using game.GenerateMap()...\n", i)
-		renderMap(i, spritesheet)
+		mapSeed := game.NewSeed()
+		fmt.Printf("Generating %s map %d with seed %d...\n", *presetName, i, mapSeed)
+		renderMap(i, cfg, mapSeed, spritesheet)
 	}
 	fmt.Println("Done!")
 }
@@ -72,9 +91,9 @@ func loadSpritesheet(path string) (*Spritesheet, error) {
 	return ss, nil
 }
 
-func renderMap(mapNum int, ss *Spritesheet) {
+func renderMap(mapNum int, cfg game.GeneratorConfig, seed int64, ss *Spritesheet) {
 	// Use the ACTUAL game map generation
-	mapData := game.GenerateMap()
+	mapData := game.Generate(cfg, seed)
 
 	// Build a lookup of objects by position
 	objectMap := make(map[string]string) // "x,y" -> object ID
@@ -85,9 +104,10 @@ func renderMap(mapNum int, ss *Spritesheet) {
 
 	// Reconstruct the floor grid from wall positions
 	// Floor tiles are where walls are NOT and are adjacent to walls
-	grid := make([][]int, game.MapSize)
+	cols, rows := mapData.Cols(), mapData.Rows()
+	grid := make([][]int, rows)
 	for y := range grid {
-		grid[y] = make([]int, game.MapSize)
+		grid[y] = make([]int, cols)
 	}
 
 	// First pass: mark all wall positions
@@ -114,7 +134,7 @@ func renderMap(mapNum int, ss *Spritesheet) {
 			for dy := -1; dy <= 1; dy++ {
 				for dx := -1; dx <= 1; dx++ {
 					nx, ny := obj.X+dx, obj.Y+dy
-					if nx >= 0 && nx < game.MapSize && ny >= 0 && ny < game.MapSize {
+					if nx >= 0 && nx < cols && ny >= 0 && ny < rows {
 						key := fmt.Sprintf("%d,%d", nx, ny)
 						if grid[ny][nx] != 1 { // Not a wall
 							floorTiles[key] = true
@@ -127,14 +147,14 @@ func renderMap(mapNum int, ss *Spritesheet) {
 
 	// Flood fill from center to get all connected floor tiles
 	visited := make(map[string]bool)
-	center := game.MapSize / 2
+	centerX, centerY := cols/2, rows/2
 
 	// Find starting floor tile near center
 	var startX, startY int = -1, -1
-	for radius := 0; radius < game.MapSize/2 && startX == -1; radius++ {
+	for radius := 0; radius < max(cols, rows)/2 && startX == -1; radius++ {
 		for dy := -radius; dy <= radius; dy++ {
 			for dx := -radius; dx <= radius; dx++ {
-				x, y := center+dx, center+dy
+				x, y := centerX+dx, centerY+dy
 				key := fmt.Sprintf("%d,%d", x, y)
 				if floorTiles[key] {
 					startX, startY = x, y
@@ -160,7 +180,7 @@ func renderMap(mapNum int, ss *Spritesheet) {
 			if visited[key] {
 				continue
 			}
-			if p.x < 0 || p.x >= game.MapSize || p.y < 0 || p.y >= game.MapSize {
+			if p.x < 0 || p.x >= cols || p.y < 0 || p.y >= rows {
 				continue
 			}
 			if grid[p.y][p.x] == 1 { // Wall
@@ -178,8 +198,8 @@ func renderMap(mapNum int, ss *Spritesheet) {
 	}
 
 	// Create image
-	tileSize := 16
-	img := image.NewRGBA(image.Rect(0, 0, game.MapSize*tileSize, game.MapSize*tileSize))
+	tileSize := game.TileSize
+	img := image.NewRGBA(image.Rect(0, 0, cols*tileSize, rows*tileSize))
 
 	// Stats
 	floorCount := 0
@@ -187,8 +207,8 @@ func renderMap(mapNum int, ss *Spritesheet) {
 	cactusCount := 0
 	chestCount := 0
 
-	for y := 0; y < game.MapSize; y++ {
-		for x := 0; x < game.MapSize; x++ {
+	for y := 0; y < rows; y++ {
+		for x := 0; x < cols; x++ {
 			destRect := image.Rect(x*tileSize, y*tileSize, (x+1)*tileSize, (y+1)*tileSize)
 			key := fmt.Sprintf("%d,%d", x, y)
 
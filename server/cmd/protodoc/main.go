@@ -0,0 +1,507 @@
+// protodoc generates PROTOCOL.md, the reference for the websocket messages
+// exchanged between the game server and its clients. It reads the server's
+// Go source rather than a hand-kept list, so the document can't drift:
+//
+//   - client messages are the cases of Client.handleMessage and the
+//     "var data struct" each case decodes its content into
+//   - server messages are the struct literals with a Type: "..." field
+//
+// Run from server/ with: go generate
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// packages are the directories searched for types, keyed by the qualifier
+// the server's source uses for them
+var packages = map[string]string{
+	"":     ".",
+	"game": "game",
+	"quiz": "quiz",
+	"wire": "wire",
+}
+
+// field is one row of a message or type table
+type field struct {
+	Name  string
+	Type  string
+	Notes string
+}
+
+// message is a documented message type
+type message struct {
+	Type   string
+	Fields []field
+}
+
+// loader holds the parsed server source
+type loader struct {
+	fset    *token.FileSet
+	files   []*ast.File // The main package, sorted by file name
+	types   map[string]*ast.TypeSpec
+	consts  map[string]string
+	pending []string        // Named types referenced but not yet documented
+	seen    map[string]bool // Named types queued for the Types section
+}
+
+func main() {
+	dir := flag.String("dir", ".", "server package directory")
+	out := flag.String("o", "", "output file (default stdout)")
+	flag.Parse()
+
+	doc, err := generate(*dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "protodoc: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *out == "" {
+		os.Stdout.Write(doc)
+		return
+	}
+	if err := os.WriteFile(*out, doc, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "protodoc: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// generate renders the protocol document for the server package in dir
+func generate(dir string) ([]byte, error) {
+	l := &loader{
+		fset:   token.NewFileSet(),
+		types:  make(map[string]*ast.TypeSpec),
+		consts: make(map[string]string),
+		seen:   make(map[string]bool),
+	}
+	for qualifier, sub := range packages {
+		if err := l.load(qualifier, filepath.Join(dir, sub)); err != nil {
+			return nil, err
+		}
+	}
+
+	version, exists := l.consts["ProtocolVersion"]
+	if !exists {
+		return nil, fmt.Errorf("ProtocolVersion not found in %s", dir)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# Game Server Protocol\n\n")
+	fmt.Fprintf(&buf, "Protocol version: **%s**\n\n", version)
+	fmt.Fprintf(&buf, "Generated by `go generate` (cmd/protodoc) from the server's Go types. Do not edit by hand.\n\n")
+
+	fmt.Fprintf(&buf, "## Envelope\n\n")
+	fmt.Fprintf(&buf, "Every client message is a JSON object (or MessagePack map, see `?encoding=msgpack`) of this shape. ")
+	fmt.Fprintf(&buf, "Server messages are flat objects whose `type` says which message it is.\n\n")
+	envelope, err := l.named("Message")
+	if err != nil {
+		return nil, err
+	}
+	writeTable(&buf, envelope)
+
+	fmt.Fprintf(&buf, "## Client messages\n\n")
+	fmt.Fprintf(&buf, "Fields listed are those of `content`.\n\n")
+	requests, err := l.clientMessages()
+	if err != nil {
+		return nil, err
+	}
+	for _, msg := range requests {
+		fmt.Fprintf(&buf, "### `%s`\n\n", msg.Type)
+		if len(msg.Fields) == 0 {
+			fmt.Fprintf(&buf, "No content.\n\n")
+			continue
+		}
+		writeTable(&buf, msg.Fields)
+	}
+
+	fmt.Fprintf(&buf, "## Server messages\n\n")
+	for _, msg := range l.serverMessages() {
+		fmt.Fprintf(&buf, "### `%s`\n\n", msg.Type)
+		writeTable(&buf, msg.Fields)
+	}
+
+	fmt.Fprintf(&buf, "## Types\n\n")
+	for len(l.pending) > 0 {
+		name := l.pending[0]
+		l.pending = l.pending[1:]
+
+		fields, err := l.named(name)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&buf, "### %s\n\n", name)
+		writeTable(&buf, fields)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// load parses one package directory, recording its types and constants
+func (l *loader) load(qualifier, dir string) error {
+	pkgs, err := parser.ParseDir(l.fset, dir, func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, parser.ParseComments)
+	if err != nil {
+		return err
+	}
+
+	prefix := ""
+	if qualifier != "" {
+		prefix = qualifier + "."
+	}
+
+	for _, pkg := range pkgs {
+		names := make([]string, 0, len(pkg.Files))
+		for name := range pkg.Files {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			file := pkg.Files[name]
+			if qualifier == "" {
+				l.files = append(l.files, file)
+			}
+			// Types declared inside functions are included, since some
+			// responses are built from them
+			ast.Inspect(file, func(n ast.Node) bool {
+				if spec, ok := n.(*ast.TypeSpec); ok {
+					l.types[prefix+spec.Name.Name] = spec
+				}
+				return true
+			})
+			for _, decl := range file.Decls {
+				gen, ok := decl.(*ast.GenDecl)
+				if !ok || gen.Tok != token.CONST {
+					continue
+				}
+				for _, spec := range gen.Specs {
+					value := spec.(*ast.ValueSpec)
+					for i, ident := range value.Names {
+						if i < len(value.Values) {
+							if lit, ok := value.Values[i].(*ast.BasicLit); ok {
+								l.consts[prefix+ident.Name] = lit.Value
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// clientMessages finds the message types handled by Client.handleMessage
+func (l *loader) clientMessages() ([]message, error) {
+	var handler *ast.FuncDecl
+	for _, file := range l.files {
+		for _, decl := range file.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Name.Name == "handleMessage" {
+				handler = fn
+			}
+		}
+	}
+	if handler == nil {
+		return nil, fmt.Errorf("handleMessage not found")
+	}
+
+	var messages []message
+	ast.Inspect(handler.Body, func(n ast.Node) bool {
+		clause, ok := n.(*ast.CaseClause)
+		if !ok || len(clause.List) == 0 {
+			return true
+		}
+		lit, ok := clause.List[0].(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING {
+			return true
+		}
+		msgType, _ := strconv.Unquote(lit.Value)
+
+		msg := message{Type: msgType}
+		for _, stmt := range clause.Body {
+			if st := contentStruct(stmt); st != nil {
+				msg.Fields = l.fields(st, "")
+			}
+		}
+		messages = append(messages, msg)
+		return false
+	})
+	return messages, nil
+}
+
+// contentStruct returns the struct of a "var data struct{...}" statement
+func contentStruct(stmt ast.Stmt) *ast.StructType {
+	decl, ok := stmt.(*ast.DeclStmt)
+	if !ok {
+		return nil
+	}
+	gen, ok := decl.Decl.(*ast.GenDecl)
+	if !ok || gen.Tok != token.VAR {
+		return nil
+	}
+	for _, spec := range gen.Specs {
+		value, ok := spec.(*ast.ValueSpec)
+		if !ok || len(value.Names) != 1 || value.Names[0].Name != "data" {
+			continue
+		}
+		if st, ok := value.Type.(*ast.StructType); ok {
+			return st
+		}
+	}
+	return nil
+}
+
+// serverMessages finds every struct literal that sets Type to a string
+func (l *loader) serverMessages() []message {
+	byType := make(map[string]message)
+	for _, file := range l.files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			lit, ok := n.(*ast.CompositeLit)
+			if !ok {
+				return true
+			}
+			msgType := literalType(lit)
+			if msgType == "" {
+				return true
+			}
+			if _, exists := byType[msgType]; exists {
+				return true
+			}
+
+			var st *ast.StructType
+			switch t := lit.Type.(type) {
+			case *ast.StructType:
+				st = t
+			case *ast.Ident:
+				if spec, exists := l.types[t.Name]; exists {
+					st, _ = spec.Type.(*ast.StructType)
+				}
+			}
+			if st != nil {
+				byType[msgType] = message{Type: msgType, Fields: l.fields(st, "")}
+			}
+			return true
+		})
+	}
+
+	messages := make([]message, 0, len(byType))
+	for _, msg := range byType {
+		messages = append(messages, msg)
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].Type < messages[j].Type
+	})
+	return messages
+}
+
+// literalType returns the value of a Type: "..." element, or ""
+func literalType(lit *ast.CompositeLit) string {
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		key, ok := kv.Key.(*ast.Ident)
+		if !ok || key.Name != "Type" {
+			continue
+		}
+		if value, ok := kv.Value.(*ast.BasicLit); ok && value.Kind == token.STRING {
+			s, _ := strconv.Unquote(value.Value)
+			return s
+		}
+	}
+	return ""
+}
+
+// named returns the fields of a named struct type such as "Player" or "game.MapData"
+func (l *loader) named(name string) ([]field, error) {
+	spec, exists := l.types[name]
+	if !exists {
+		return nil, fmt.Errorf("type %s not found", name)
+	}
+	st, ok := spec.Type.(*ast.StructType)
+	if !ok {
+		return nil, fmt.Errorf("type %s is not a struct", name)
+	}
+	return l.fields(st, qualifierOf(name)), nil
+}
+
+// qualifierOf returns "game" for "game.MapData" and "" for "Player"
+func qualifierOf(name string) string {
+	if i := strings.Index(name, "."); i >= 0 {
+		return name[:i]
+	}
+	return ""
+}
+
+// fields lists the JSON fields of a struct, inlining embedded structs the
+// way encoding/json does. qualifier is the package the struct was declared in.
+func (l *loader) fields(st *ast.StructType, qualifier string) []field {
+	var fields []field
+	for _, f := range st.Fields.List {
+		tag := ""
+		if f.Tag != nil {
+			raw, _ := strconv.Unquote(f.Tag.Value)
+			tag = reflect.StructTag(raw).Get("json")
+		}
+		jsonName, options, _ := strings.Cut(tag, ",")
+		if jsonName == "-" {
+			continue
+		}
+
+		notes := ""
+		if f.Comment != nil {
+			notes = strings.TrimSpace(f.Comment.Text())
+		} else if f.Doc != nil {
+			notes = strings.TrimSpace(f.Doc.Text())
+		}
+		if strings.Contains(options, "omitempty") {
+			notes = strings.TrimSuffix("Optional. "+notes, " ")
+		}
+
+		// Embedded struct without a name of its own: its fields are promoted
+		if len(f.Names) == 0 && jsonName == "" {
+			if name := l.typeName(f.Type, qualifier); name != "" {
+				if spec, exists := l.types[name]; exists {
+					if embedded, ok := spec.Type.(*ast.StructType); ok {
+						fields = append(fields, l.fields(embedded, qualifierOf(name))...)
+						continue
+					}
+				}
+			}
+		}
+
+		names := f.Names
+		if len(names) == 0 {
+			names = []*ast.Ident{ast.NewIdent(l.typeName(f.Type, qualifier))}
+		}
+		for _, ident := range names {
+			if !ident.IsExported() {
+				continue
+			}
+			name := jsonName
+			if name == "" {
+				name = ident.Name
+			}
+			fields = append(fields, field{
+				Name:  name,
+				Type:  l.fieldType(f, qualifier),
+				Notes: strings.ReplaceAll(notes, "\n", " "),
+			})
+		}
+	}
+	return fields
+}
+
+// fieldType describes a field's type. Pre-encoded json.RawMessage fields
+// whose comment starts with a type name, such as "// game.MapData", are
+// documented as that type.
+func (l *loader) fieldType(f *ast.Field, qualifier string) string {
+	if l.typeName(f.Type, qualifier) == "json.RawMessage" && f.Comment != nil {
+		words := strings.Fields(f.Comment.Text())
+		if len(words) > 0 {
+			named := strings.TrimRight(words[0], ",.")
+			if _, exists := l.types[named]; exists {
+				pkg, name, found := strings.Cut(named, ".")
+				if !found {
+					return l.jsonType(ast.NewIdent(pkg), "")
+				}
+				return l.jsonType(&ast.SelectorExpr{X: ast.NewIdent(pkg), Sel: ast.NewIdent(name)}, "")
+			}
+		}
+	}
+	return l.jsonType(f.Type, qualifier)
+}
+
+// typeName returns the qualified name of a named type expression, or ""
+func (l *loader) typeName(expr ast.Expr, qualifier string) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if qualifier != "" {
+			return qualifier + "." + t.Name
+		}
+		return t.Name
+	case *ast.SelectorExpr:
+		if pkg, ok := t.X.(*ast.Ident); ok {
+			return pkg.Name + "." + t.Sel.Name
+		}
+	case *ast.StarExpr:
+		return l.typeName(t.X, qualifier)
+	}
+	return ""
+}
+
+// jsonType describes how a Go type appears on the wire
+func (l *loader) jsonType(expr ast.Expr, qualifier string) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return l.jsonType(t.X, qualifier)
+	case *ast.ArrayType:
+		return "array of " + l.jsonType(t.Elt, qualifier)
+	case *ast.MapType:
+		return "object of " + l.jsonType(t.Value, qualifier)
+	case *ast.InterfaceType:
+		return "any"
+	case *ast.StructType:
+		return "object"
+	case *ast.SelectorExpr:
+		switch l.typeName(t, qualifier) {
+		case "json.RawMessage":
+			return "any JSON"
+		case "time.Time":
+			return "string (RFC 3339 time)"
+		}
+	case *ast.Ident:
+		switch t.Name {
+		case "string":
+			return "string"
+		case "bool":
+			return "boolean"
+		case "int", "int8", "int16", "int32", "int64",
+			"uint", "uint8", "uint16", "uint32", "uint64",
+			"float32", "float64":
+			return "number"
+		}
+	}
+
+	// A named type from the server's packages
+	name := l.typeName(expr, qualifier)
+	spec, exists := l.types[name]
+	if !exists {
+		return name
+	}
+	if _, isStruct := spec.Type.(*ast.StructType); isStruct {
+		if !l.seen[name] {
+			l.seen[name] = true
+			l.pending = append(l.pending, name)
+		}
+		return fmt.Sprintf("[%s](#%s)", name, anchor(name))
+	}
+	// Named basic types such as GamePhase read as their underlying type
+	return fmt.Sprintf("%s (%s)", l.jsonType(spec.Type, qualifierOf(name)), name)
+}
+
+// anchor returns the Markdown heading anchor for a type name
+func anchor(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, ".", ""))
+}
+
+// writeTable renders fields as a Markdown table
+func writeTable(buf *bytes.Buffer, fields []field) {
+	fmt.Fprintf(buf, "| Field | Type | Notes |\n|---|---|---|\n")
+	for _, f := range fields {
+		fmt.Fprintf(buf, "| `%s` | %s | %s |\n", f.Name, f.Type, strings.ReplaceAll(f.Notes, "|", "\\|"))
+	}
+	fmt.Fprintf(buf, "\n")
+}
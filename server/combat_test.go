@@ -0,0 +1,284 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/AmeenAhmed/hackathon/game"
+	"github.com/AmeenAhmed/hackathon/wire"
+)
+
+// openTestCollision is 40x40 tiles of floor with nothing in the way
+func openTestCollision() *game.CollisionMap {
+	const size = 40
+	mapData := &game.MapData{Width: size * game.TileSize, Height: size * game.TileSize}
+	for y := 0; y < size; y++ {
+		mapData.Terrain = append(mapData.Terrain, make([]int, size))
+	}
+	return game.NewCollisionMap(mapData)
+}
+
+// newCombatRoom is a running match on an open map, registered so handlers
+// can find it
+func newCombatRoom(t *testing.T) *Room {
+	t.Helper()
+	r := newTestRoom(t)
+	r.collision = openTestCollision()
+	r.GameState.GamePhase = PhasePlaying
+
+	roomManager.mutex.Lock()
+	roomManager.rooms[r.Code] = r
+	roomManager.mutex.Unlock()
+	t.Cleanup(func() {
+		roomManager.mutex.Lock()
+		delete(roomManager.rooms, r.Code)
+		roomManager.mutex.Unlock()
+	})
+	return r
+}
+
+// addTestPlayer puts a living player with starting ammo in the room.
+// Replies to the returned client collect in its Send channel.
+func addTestPlayer(r *Room, id string, x, y float64) *Client {
+	player := &Player{ID: id, X: x, Y: y, Health: 100, MaxHealth: 100, Ammo: game.StartingAmmo()}
+	r.GameState.Players[id] = player
+	return &Client{ID: id, RoomCode: r.Code, Player: player, Send: make(chan *wire.Message, 16)}
+}
+
+// lastError returns the code of the newest error sent to the client, or ""
+func lastError(c *Client) ErrorCode {
+	var code ErrorCode
+	for {
+		select {
+		case msg := <-c.Send:
+			var reply errorMessage
+			if json.Unmarshal(msg.JSON, &reply) == nil && reply.Type == "error" {
+				code = reply.Code
+			}
+		default:
+			return code
+		}
+	}
+}
+
+func TestBulletSpawn(t *testing.T) {
+	tests := []struct {
+		name     string
+		setup    func(r *Room, shooter *Player)
+		bulletID string
+		gunType  int
+		wantErr  ErrorCode
+	}{
+		{"accepted", nil, "b1", game.GunPistol, ""},
+		{"same ID as another owner's bullet", func(r *Room, shooter *Player) {
+			r.bullets[bulletKey{owner: "p2", id: "b1"}] = game.NewBullet("b1", "p2", 0, 0, 1, 0, game.GunPistol)
+		}, "b1", game.GunPistol, ""},
+		{"duplicate ID", func(r *Room, shooter *Player) {
+			r.bullets[bulletKey{owner: "p1", id: "b1"}] = game.NewBullet("b1", "p1", 0, 0, 1, 0, game.GunPistol)
+		}, "b1", game.GunPistol, CodeInvalidRequest},
+		{"missing ID", nil, "", game.GunPistol, CodeInvalidRequest},
+		{"unknown gun", nil, "b1", 7, CodeInvalidRequest},
+		{"gun out of ammo", func(r *Room, shooter *Player) {
+			shooter.Ammo[game.GunUzi] = 0
+		}, "b1", game.GunUzi, CodeForbidden},
+		{"dead shooter", func(r *Room, shooter *Player) {
+			shooter.Health = 0
+		}, "b1", game.GunPistol, CodeForbidden},
+		{"paused", func(r *Room, shooter *Player) {
+			r.GameState.GamePhase = PhasePaused
+		}, "b1", game.GunPistol, CodeInvalidPhase},
+		{"lobby", func(r *Room, shooter *Player) {
+			r.GameState.GamePhase = PhaseLobby
+		}, "b1", game.GunPistol, CodeInvalidPhase},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newCombatRoom(t)
+			c := addTestPlayer(r, "p1", 100, 100)
+			if tt.setup != nil {
+				tt.setup(r, c.Player)
+			}
+			before := len(r.bullets)
+			ammo := c.Player.Ammo[game.GunPistol]
+
+			c.handleBulletSpawn(tt.bulletID, 100, 100, 1, 0, 0, tt.gunType)
+
+			if got := lastError(c); got != tt.wantErr {
+				t.Fatalf("error %q, want %q", got, tt.wantErr)
+			}
+			if tt.wantErr != "" {
+				if len(r.bullets) != before {
+					t.Errorf("rejected bullet was tracked")
+				}
+				return
+			}
+			bullet, tracked := r.bullets[bulletKey{owner: "p1", id: tt.bulletID}]
+			if !tracked || bullet.OwnerID != "p1" {
+				t.Fatalf("bullet not tracked under its owner: %v", r.bullets)
+			}
+			if c.Player.Ammo[game.GunPistol] != ammo-1 {
+				t.Errorf("pistol ammo %d, want %d", c.Player.Ammo[game.GunPistol], ammo-1)
+			}
+		})
+	}
+}
+
+func TestBulletSpawnSnapsToShooter(t *testing.T) {
+	r := newCombatRoom(t)
+	c := addTestPlayer(r, "p1", 100, 100)
+
+	c.handleBulletSpawn("far", 500, 500, 1, 0, 0, game.GunPistol)
+
+	bullet := r.bullets[bulletKey{owner: "p1", id: "far"}]
+	if bullet == nil || bullet.X != 100 || bullet.Y != 100 {
+		t.Fatalf("bullet fired from across the map: %+v", bullet)
+	}
+}
+
+func TestUpdateBulletsHits(t *testing.T) {
+	tests := []struct {
+		name         string
+		setup        func(r *Room, target *Player)
+		owner        string // Who fired the bullet
+		wantHealth   float64
+		wantBullet   bool // Bullet still flying afterwards
+		wantKills    int
+		wantDiedAt   bool
+		friendlyFire bool
+	}{
+		{"hit", nil, "p1", 75, false, 0, false, true},
+		{"kill", func(r *Room, target *Player) { target.Health = 20 }, "p1", 0, false, 1, true, true},
+		{"protected target", func(r *Room, target *Player) { target.IsProtected = true }, "p1", 100, true, 0, false, true},
+		{"disconnected target", func(r *Room, target *Player) { target.Disconnected = true }, "p1", 100, true, 0, false, true},
+		{"dead target", func(r *Room, target *Player) { target.Health = 0 }, "p1", 0, true, 0, false, true},
+		{"own bullet", nil, "p2", 100, true, 0, false, true},
+		{"friendly fire off", nil, "p1", 100, true, 0, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newCombatRoom(t)
+			r.Settings.FriendlyFire = tt.friendlyFire
+			shooter := addTestPlayer(r, "p1", 100, 300).Player
+			target := addTestPlayer(r, "p2", 300, 300).Player
+			if tt.setup != nil {
+				tt.setup(r, target)
+			}
+
+			// Just short of the target, moving towards it
+			key := bulletKey{owner: tt.owner, id: "b1"}
+			r.bullets[key] = game.NewBullet("b1", tt.owner, target.X-3, target.Y, 1, 0, game.GunPistol)
+
+			r.updateBullets()
+
+			if target.Health != tt.wantHealth {
+				t.Errorf("target health %v, want %v", target.Health, tt.wantHealth)
+			}
+			if _, flying := r.bullets[key]; flying != tt.wantBullet {
+				t.Errorf("bullet still flying %v, want %v", flying, tt.wantBullet)
+			}
+			if shooter.Kills != tt.wantKills {
+				t.Errorf("shooter kills %d, want %d", shooter.Kills, tt.wantKills)
+			}
+			if !target.DiedAt.IsZero() != tt.wantDiedAt {
+				t.Errorf("target died at %v, want death %v", target.DiedAt, tt.wantDiedAt)
+			}
+			if tt.wantDiedAt && target.QuizBudget != QuizQuestions {
+				t.Errorf("quiz budget %d after dying, want %d", target.QuizBudget, QuizQuestions)
+			}
+		})
+	}
+}
+
+func TestUpdateBulletsOnlyWhilePlaying(t *testing.T) {
+	for _, phase := range []GamePhase{PhaseLobby, PhaseCountdown, PhasePaused, PhaseEnded} {
+		r := newCombatRoom(t)
+		r.GameState.GamePhase = phase
+		target := addTestPlayer(r, "p2", 300, 300).Player
+		key := bulletKey{owner: "p1", id: "b1"}
+		bullet := game.NewBullet("b1", "p1", target.X-3, target.Y, 1, 0, game.GunPistol)
+		r.bullets[key] = bullet
+
+		r.updateBullets()
+
+		if target.Health != 100 || bullet.X != target.X-3 {
+			t.Errorf("%s: bullet moved or hit (health %v, x %v)", phase, target.Health, bullet.X)
+		}
+	}
+}
+
+func TestQuizProtection(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name   string
+		player Player
+		want   bool
+	}{
+		{"out of ammo", Player{Health: 100}, true},
+		{"ammo left", Player{Health: 100, Ammo: game.Ammo{game.GunShotgun: 1}}, false},
+		{"dead", Player{Health: 0}, false},
+		{"cooling down", Player{Health: 100, QuizCooldownEnds: now.Add(time.Second)}, false},
+		{"cooldown over", Player{Health: 100, QuizCooldownEnds: now.Add(-time.Second)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := tt.player
+			if got := p.startQuizProtection(now); got != tt.want {
+				t.Fatalf("protected %v, want %v", got, tt.want)
+			}
+			if p.IsProtected != tt.want {
+				t.Errorf("IsProtected %v, want %v", p.IsProtected, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuizProtectionEnds(t *testing.T) {
+	tests := []struct {
+		name string
+		end  func(r *Room, c *Client)
+	}{
+		{"moving", func(r *Room, c *Client) {
+			c.Player.MovedAt = time.Now().Add(-time.Second)
+			r.updatePlayerPosition(c.ID, c.Player.X+10, c.Player.Y, "run", "right", 0, false, 0)
+		}},
+		{"expiring", func(r *Room, c *Client) {
+			c.Player.refreshProtection(time.Now().Add(QuizProtectionDuration + time.Second))
+		}},
+		{"reloading", func(r *Room, c *Client) {
+			c.Player.QuizBudget = 1
+			c.Player.answeredQuestion(time.Now())
+			if c.Player.Ammo != game.FullAmmo() {
+				t.Errorf("ammo %v after reloading, want %v", c.Player.Ammo, game.FullAmmo())
+			}
+		}},
+		{"shooting", func(r *Room, c *Client) {
+			c.Player.Ammo[game.GunPistol] = 1
+			c.handleBulletSpawn("b1", c.Player.X, c.Player.Y, 1, 0, 0, game.GunPistol)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newCombatRoom(t)
+			c := addTestPlayer(r, "p1", 100, 100)
+			c.Player.Ammo = game.Ammo{}
+			if !c.Player.startQuizProtection(time.Now()) {
+				t.Fatal("player out of ammo wasn't protected")
+			}
+
+			tt.end(r, c)
+
+			if c.Player.IsProtected || !c.Player.QuizProtection.IsZero() {
+				t.Errorf("still protected")
+			}
+			if c.Player.QuizCooldownEnds.IsZero() {
+				t.Errorf("cooldown not started")
+			}
+		})
+	}
+}
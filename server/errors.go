@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+
+	"github.com/AmeenAhmed/hackathon/wire"
+)
+
+// ErrorCode is the machine-readable reason a client action was rejected
+type ErrorCode string
+
+const (
+	CodeBadMessage      ErrorCode = "bad_message"      // Frame or content couldn't be parsed
+	CodeUnknownType     ErrorCode = "unknown_type"     // No such message type
+	CodeRateLimited     ErrorCode = "rate_limited"     // Dropped by the connection's rate limits
+	CodeNotInRoom       ErrorCode = "not_in_room"      // Needs a joined room first
+	CodeRoomNotFound    ErrorCode = "room_not_found"   // Room code unknown or room closed
+	CodeRoomFull        ErrorCode = "room_full"        // Room is at maxPlayers
+	CodeNotDashboard    ErrorCode = "not_dashboard"    // Only the room's dashboard may do this
+	CodeNotPlayer       ErrorCode = "not_player"       // Only a player may do this
+	CodeForbidden       ErrorCode = "forbidden"        // Not allowed for this player right now
+	CodeInvalidSession  ErrorCode = "invalid_session"  // Missing or wrong reconnect token
+	CodeInvalidSettings ErrorCode = "invalid_settings" // Room settings out of range
+	CodeInvalidBank     ErrorCode = "invalid_bank"     // Question bank missing or unparseable
+	CodeInvalidPhase    ErrorCode = "invalid_phase"    // Not allowed in the current game phase
+	CodeInvalidRequest  ErrorCode = "invalid_request"  // Arguments failed validation
+	CodeTooEarly        ErrorCode = "too_early"        // A cooldown hasn't elapsed yet
+	CodeNoQuestions     ErrorCode = "no_questions"     // Question bank has nothing left to ask
+	CodeInternal        ErrorCode = "internal"         // Server-side failure
+
+	CodeHelloRequired      ErrorCode = "hello_required"      // Send hello before anything else
+	CodeUnsupportedVersion ErrorCode = "unsupported_version" // Client protocol too old or too new
+)
+
+// errorMessage is sent to a client whenever one of its messages is rejected.
+// Error is human-readable; clients should branch on Code.
+type errorMessage struct {
+	Type        string    `json:"type"`
+	Code        ErrorCode `json:"code"`
+	Error       string    `json:"error"`
+	RequestType string    `json:"requestType,omitempty"` // Type of the rejected message
+	RequestID   string    `json:"requestId,omitempty"`   // Echoed from the rejected message
+}
+
+// sendError rejects the message currently being handled, to this client only
+func (c *Client) sendError(code ErrorCode, message string) {
+	response := errorMessage{
+		Type:        "error",
+		Code:        code,
+		Error:       message,
+		RequestType: c.request.Type,
+		RequestID:   c.request.RequestID,
+	}
+	data, _ := json.Marshal(response)
+	c.Send <- wire.NewMessage(data)
+}
+
+// badContent rejects a message whose content didn't parse
+func (c *Client) badContent(err error) {
+	log.Printf("Error parsing %s message from client %s: %v", c.request.Type, c.ID, err)
+	c.sendError(CodeBadMessage, "Invalid "+c.request.Type+" content")
+}
+
+// requireRoom returns the room the client is in, or rejects the message
+func (c *Client) requireRoom() (*Room, bool) {
+	if c.RoomCode == "" {
+		c.sendError(CodeNotInRoom, "Not in a room")
+		return nil, false
+	}
+	room, exists := roomManager.GetRoom(c.RoomCode)
+	if !exists {
+		c.sendError(CodeRoomNotFound, "Room not found")
+		return nil, false
+	}
+	return room, true
+}
+
+// requirePlayer returns the room of a client that joined as a player,
+// or rejects the message
+func (c *Client) requirePlayer() (*Room, bool) {
+	if c.IsDashboard {
+		c.sendError(CodeNotPlayer, "Only players can do that")
+		return nil, false
+	}
+	if c.Player == nil {
+		c.sendError(CodeNotInRoom, "Not in a room")
+		return nil, false
+	}
+	return c.requireRoom()
+}
+
+// requireDashboard returns the room of a dashboard client, or rejects the message
+func (c *Client) requireDashboard() (*Room, bool) {
+	if !c.IsDashboard {
+		log.Printf("Non-dashboard client %s sent %s", c.ID, c.request.Type)
+		c.sendError(CodeNotDashboard, "Only the dashboard can do that")
+		return nil, false
+	}
+	return c.requireRoom()
+}
@@ -0,0 +1,211 @@
+package game
+
+import "math"
+
+// =============================================================================
+// SERVER-SIDE COMBAT SIMULATION
+// Bullets are simulated on the server so hits and damage can't be forged
+// by a modified client. Numbers mirror the Phaser client in MainScene.ts.
+// =============================================================================
+
+// Gun types - must match the client's currentGun index
+const (
+	GunPistol  = 0
+	GunShotgun = 1
+	GunUzi     = 2
+)
+
+const (
+	TileSize = 16 // Pixel size of one terrain tile
+
+	BulletSpeed     = 400.0  // Pixels per second
+	BulletRange     = 1500.0 // Max travel distance before a bullet expires
+	BulletRadius    = 2.0    // Collision radius of a bullet
+	PlayerHitRadius = 8.0    // Half of the 16x16 player sprite
+
+	// MaxMuzzleOffset is how far from the shooter a bullet may spawn
+	// before the server snaps it back to the shooter's position
+	MaxMuzzleOffset = 48.0
+)
+
+// IsValidGun reports whether gunType is a known gun
+func IsValidGun(gunType int) bool {
+	return gunType >= GunPistol && gunType <= GunUzi
+}
+
+// GunDamage returns the damage a single bullet of the given gun deals
+func GunDamage(gunType int) float64 {
+	switch gunType {
+	case GunShotgun:
+		return 15 // Less damage per pellet
+	case GunPistol, GunUzi:
+		return 25
+	default:
+		return 0
+	}
+}
+
+// ShotgunPellets is how many bullets one shotgun shell fires
+const ShotgunPellets = 3
+
+// Ammo is the bullets left in each gun, indexed by gun type. It's counted
+// in bullets, so a shotgun shell is ShotgunPellets of them.
+type Ammo [GunUzi + 1]int
+
+// StartingAmmo is what a player carries after spawning
+func StartingAmmo() Ammo {
+	return Ammo{GunPistol: 6, GunShotgun: 4 * ShotgunPellets, GunUzi: 15}
+}
+
+// FullAmmo is what a player carries after reloading
+func FullAmmo() Ammo {
+	return Ammo{GunPistol: 6, GunShotgun: 8 * ShotgunPellets, GunUzi: 30}
+}
+
+// Empty reports whether every gun is out of bullets
+func (a Ammo) Empty() bool {
+	for _, bullets := range a {
+		if bullets > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Bullet is a projectile simulated by the server
+type Bullet struct {
+	ID       string
+	OwnerID  string
+	X, Y     float64
+	VX, VY   float64
+	GunType  int
+	Traveled float64
+}
+
+// NewBullet creates a bullet travelling along (dirX, dirY) at BulletSpeed.
+// The direction is normalized so clients can't send faster bullets.
+func NewBullet(id, ownerID string, x, y, dirX, dirY float64, gunType int) *Bullet {
+	length := math.Hypot(dirX, dirY)
+	if length == 0 {
+		dirX, dirY, length = 1, 0, 1
+	}
+	return &Bullet{
+		ID:      id,
+		OwnerID: ownerID,
+		X:       x,
+		Y:       y,
+		VX:      dirX / length * BulletSpeed,
+		VY:      dirY / length * BulletSpeed,
+		GunType: gunType,
+	}
+}
+
+// Step advances the bullet by dt seconds. It returns the segment travelled
+// (so callers can sweep it against players) and whether the bullet is still
+// alive after hitting walls or running out of range.
+func (b *Bullet) Step(dt float64, cm *CollisionMap) (fromX, fromY float64, alive bool) {
+	fromX, fromY = b.X, b.Y
+
+	// Sub-step so a bullet never skips over a whole tile in one tick
+	dist := math.Hypot(b.VX, b.VY) * dt
+	steps := int(math.Ceil(dist / (TileSize / 2)))
+	if steps < 1 {
+		steps = 1
+	}
+	sx, sy := b.VX*dt/float64(steps), b.VY*dt/float64(steps)
+
+	for i := 0; i < steps; i++ {
+		b.X += sx
+		b.Y += sy
+		if cm.IsSolidAt(b.X, b.Y) {
+			return fromX, fromY, false
+		}
+	}
+
+	b.Traveled += dist
+	return fromX, fromY, b.Traveled < BulletRange
+}
+
+// Path returns where the bullet was fired from and where it runs out of range
+func (b *Bullet) Path() (x1, y1, x2, y2 float64) {
+	dirX, dirY := b.VX/BulletSpeed, b.VY/BulletSpeed
+	x1, y1 = b.X-dirX*b.Traveled, b.Y-dirY*b.Traveled
+	return x1, y1, x1 + dirX*BulletRange, y1 + dirY*BulletRange
+}
+
+// SegmentHitsCircle reports whether the segment (x1,y1)-(x2,y2) passes
+// within radius r of the point (cx, cy)
+func SegmentHitsCircle(x1, y1, x2, y2, cx, cy, r float64) bool {
+	dx, dy := x2-x1, y2-y1
+	lengthSq := dx*dx + dy*dy
+
+	t := 0.0
+	if lengthSq > 0 {
+		t = ((cx-x1)*dx + (cy-y1)*dy) / lengthSq
+		t = math.Max(0, math.Min(1, t))
+	}
+
+	px, py := x1+t*dx, y1+t*dy
+	return (px-cx)*(px-cx)+(py-cy)*(py-cy) <= r*r
+}
+
+// -----------------------------------------------------------------------------
+// COLLISION MAP
+// Tile-resolution lookup of everything that stops bullets and players
+// -----------------------------------------------------------------------------
+
+// CollisionMap marks tiles blocked by walls and cover objects, and the
+// tiles outside the outer walls that players may never stand on
+type CollisionMap struct {
+	cols, rows int
+	solid      []bool
+	outside    []bool
+}
+
+// IsBlockingObject reports whether a map object ID blocks movement and bullets
+// "7"/"8" = walls, "9" = cactus cover
+func IsBlockingObject(id string) bool {
+	return id == "7" || id == "8" || id == "9"
+}
+
+// NewCollisionMap builds the collision lookup for a generated map
+func NewCollisionMap(mapData *MapData) *CollisionMap {
+	cm := &CollisionMap{
+		cols: mapData.Width / TileSize,
+		rows: mapData.Height / TileSize,
+	}
+	cm.solid = make([]bool, cm.cols*cm.rows)
+	cm.outside = make([]bool, cm.cols*cm.rows)
+
+	for y := 0; y < cm.rows && y < len(mapData.Terrain); y++ {
+		for x := 0; x < cm.cols && x < len(mapData.Terrain[y]); x++ {
+			cm.outside[y*cm.cols+x] = mapData.Terrain[y][x] == TileOutside
+		}
+	}
+
+	for _, obj := range mapData.MapObjects {
+		if IsBlockingObject(obj.ID) && cm.inBounds(obj.X, obj.Y) {
+			cm.solid[obj.Y*cm.cols+obj.X] = true
+		}
+	}
+
+	return cm
+}
+
+func (cm *CollisionMap) inBounds(tx, ty int) bool {
+	return tx >= 0 && tx < cm.cols && ty >= 0 && ty < cm.rows
+}
+
+// IsSolid reports whether the tile at (tx, ty) is blocked.
+// Anything outside the map counts as solid.
+func (cm *CollisionMap) IsSolid(tx, ty int) bool {
+	if !cm.inBounds(tx, ty) {
+		return true
+	}
+	return cm.solid[ty*cm.cols+tx]
+}
+
+// IsSolidAt reports whether the pixel position (x, y) is inside a blocked tile
+func (cm *CollisionMap) IsSolidAt(x, y float64) bool {
+	return cm.IsSolid(int(math.Floor(x/TileSize)), int(math.Floor(y/TileSize)))
+}
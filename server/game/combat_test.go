@@ -0,0 +1,116 @@
+package game
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNewBullet(t *testing.T) {
+	tests := []struct {
+		name       string
+		dirX, dirY float64
+		wantVX     float64
+		wantVY     float64
+	}{
+		{"unit direction", 1, 0, BulletSpeed, 0},
+		{"long direction is normalized", 0, -50, 0, -BulletSpeed},
+		{"diagonal", 3, 4, BulletSpeed * 0.6, BulletSpeed * 0.8},
+		{"zero direction fires right", 0, 0, BulletSpeed, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBullet("b1", "p1", 10, 20, tt.dirX, tt.dirY, GunPistol)
+			if math.Abs(b.VX-tt.wantVX) > 1e-9 || math.Abs(b.VY-tt.wantVY) > 1e-9 {
+				t.Errorf("velocity (%v, %v), want (%v, %v)", b.VX, b.VY, tt.wantVX, tt.wantVY)
+			}
+		})
+	}
+}
+
+func TestBulletStep(t *testing.T) {
+	// Floor with a wall down column 10, as in movementTestMap
+	cm := movementTestMap()
+	wallX := 10.0 * TileSize
+
+	tests := []struct {
+		name      string
+		x, y      float64
+		traveled  float64
+		dt        float64
+		wantAlive bool
+		wantX     float64
+	}{
+		{"open floor", 40, 40, 0, 0.1, true, 80},
+		{"into a wall", wallX - 10, 40, 0, 0.1, false, -1},
+		{"fast enough to skip a tile", wallX - 10, 40, 0, 1, false, -1},
+		{"out of range", 40, 40, BulletRange - 10, 0.1, false, 80},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBullet("b1", "p1", tt.x, tt.y, 1, 0, GunPistol)
+			b.Traveled = tt.traveled
+
+			fromX, fromY, alive := b.Step(tt.dt, cm)
+			if alive != tt.wantAlive {
+				t.Fatalf("alive %v, want %v", alive, tt.wantAlive)
+			}
+			if fromX != tt.x || fromY != tt.y {
+				t.Errorf("segment starts at (%v, %v), want (%v, %v)", fromX, fromY, tt.x, tt.y)
+			}
+			if tt.wantX >= 0 && math.Abs(b.X-tt.wantX) > 1e-9 {
+				t.Errorf("x %v, want %v", b.X, tt.wantX)
+			}
+			if !alive && tt.wantX < 0 && b.X > wallX+TileSize {
+				t.Errorf("bullet passed through the wall to x %v", b.X)
+			}
+		})
+	}
+}
+
+func TestSegmentHitsCircle(t *testing.T) {
+	tests := []struct {
+		name           string
+		x1, y1, x2, y2 float64
+		want           bool
+	}{
+		{"passes through", 0, 0, 20, 0, true},
+		{"grazes the edge", 0, 5, 20, 5, true},
+		{"passes beside", 0, 6, 20, 6, false},
+		{"stops short", -20, 0, -6, 0, false},
+		{"ends inside", -20, 0, -4, 0, true},
+		{"starts beyond", 6, 0, 20, 0, false},
+		{"zero length inside", 3, 0, 3, 0, true},
+		{"zero length outside", 8, 0, 8, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SegmentHitsCircle(tt.x1, tt.y1, tt.x2, tt.y2, 0, 0, 5); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAmmoEmpty(t *testing.T) {
+	tests := []struct {
+		name string
+		ammo Ammo
+		want bool
+	}{
+		{"none", Ammo{}, true},
+		{"starting", StartingAmmo(), false},
+		{"full", FullAmmo(), false},
+		{"one uzi bullet", Ammo{GunUzi: 1}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.ammo.Empty(); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package game
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// CompactMapVersion identifies the CompactMap tile layout
+const CompactMapVersion = 1
+
+// CompactMap is MapData packed for sending to clients. Tiles holds two
+// Rows x Cols byte grids in row-major order, gzipped and base64 encoded:
+// first the terrain value plus one (so TileOutside is 0), then the ID of
+// the object on each tile, 0 for none. IsPicked isn't carried, since maps
+// are only ever sent as generated.
+type CompactMap struct {
+	Version    int         `json:"version"`
+	Width      int         `json:"width"`  // Pixels, as in MapData
+	Height     int         `json:"height"` // Pixels, as in MapData
+	Seed       int64       `json:"seed"`
+	Tiles      string      `json:"tiles"`
+	SpawnZones []SpawnZone `json:"spawnZones"`
+}
+
+// Pack encodes a map as a CompactMap. It fails for maps it can't represent,
+// such as two objects on one tile.
+func Pack(m *MapData) (CompactMap, error) {
+	cols, rows := m.Cols(), m.Rows()
+	if len(m.Terrain) != rows {
+		return CompactMap{}, fmt.Errorf("terrain has %d rows, want %d", len(m.Terrain), rows)
+	}
+
+	tiles := make([]byte, 2*cols*rows)
+	objects := tiles[cols*rows:]
+
+	for y, row := range m.Terrain {
+		if len(row) != cols {
+			return CompactMap{}, fmt.Errorf("terrain row %d has %d tiles, want %d", y, len(row), cols)
+		}
+		for x, value := range row {
+			if value < TileOutside || value > 254 {
+				return CompactMap{}, fmt.Errorf("terrain value %d at %d,%d out of range", value, x, y)
+			}
+			tiles[y*cols+x] = byte(value + 1)
+		}
+	}
+
+	for _, obj := range m.MapObjects {
+		id, err := strconv.Atoi(obj.ID)
+		if err != nil || id < 1 || id > 255 {
+			return CompactMap{}, fmt.Errorf("object ID %q can't be packed", obj.ID)
+		}
+		if obj.X < 0 || obj.X >= cols || obj.Y < 0 || obj.Y >= rows {
+			return CompactMap{}, fmt.Errorf("object at %d,%d is off the map", obj.X, obj.Y)
+		}
+		i := obj.Y*cols + obj.X
+		if objects[i] != 0 {
+			return CompactMap{}, fmt.Errorf("two objects at %d,%d", obj.X, obj.Y)
+		}
+		objects[i] = byte(id)
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write(tiles)
+	if err := zw.Close(); err != nil {
+		return CompactMap{}, err
+	}
+
+	return CompactMap{
+		Version:    CompactMapVersion,
+		Width:      m.Width,
+		Height:     m.Height,
+		Seed:       m.Seed,
+		Tiles:      base64.StdEncoding.EncodeToString(buf.Bytes()),
+		SpawnZones: m.SpawnZones,
+	}, nil
+}
+
+// Unpack decodes a CompactMap. Objects come back in row-major order.
+func (c CompactMap) Unpack() (MapData, error) {
+	if c.Version != CompactMapVersion {
+		return MapData{}, fmt.Errorf("unsupported compact map version %d", c.Version)
+	}
+
+	packed, err := base64.StdEncoding.DecodeString(c.Tiles)
+	if err != nil {
+		return MapData{}, err
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(packed))
+	if err != nil {
+		return MapData{}, err
+	}
+	tiles, err := io.ReadAll(zr)
+	if err != nil {
+		return MapData{}, err
+	}
+
+	m := MapData{
+		Width:      c.Width,
+		Height:     c.Height,
+		Seed:       c.Seed,
+		MapObjects: []MapObject{},
+		SpawnZones: c.SpawnZones,
+	}
+	cols, rows := m.Cols(), m.Rows()
+	if len(tiles) != 2*cols*rows {
+		return MapData{}, fmt.Errorf("got %d tile bytes, want %d", len(tiles), 2*cols*rows)
+	}
+
+	m.Terrain = make([][]int, rows)
+	for y := range m.Terrain {
+		m.Terrain[y] = make([]int, cols)
+		for x := range m.Terrain[y] {
+			i := y*cols + x
+			m.Terrain[y][x] = int(tiles[i]) - 1
+			if id := tiles[cols*rows+i]; id != 0 {
+				m.MapObjects = append(m.MapObjects, MapObject{ID: strconv.Itoa(int(id)), X: x, Y: y})
+			}
+		}
+	}
+	return m, nil
+}
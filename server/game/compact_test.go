@@ -0,0 +1,132 @@
+package game
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// sortObjects puts objects in the row-major order Unpack returns them in
+func sortObjects(objects []MapObject) []MapObject {
+	sorted := append([]MapObject{}, objects...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Y != sorted[j].Y {
+			return sorted[i].Y < sorted[j].Y
+		}
+		return sorted[i].X < sorted[j].X
+	})
+	return sorted
+}
+
+func TestCompactRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  GeneratorConfig
+		seed int64
+	}{
+		{"small", SmallMap, 1},
+		{"small other seed", SmallMap, 42},
+		{"medium", MediumMap, 7},
+		{"full", FullMap, MaxSeed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := Generate(tt.cfg, tt.seed)
+			packed, err := Pack(&original)
+			if err != nil {
+				t.Fatalf("Pack: %v", err)
+			}
+			got, err := packed.Unpack()
+			if err != nil {
+				t.Fatalf("Unpack: %v", err)
+			}
+
+			if got.Width != original.Width || got.Height != original.Height || got.Seed != original.Seed {
+				t.Errorf("got %dx%d seed %d, want %dx%d seed %d",
+					got.Width, got.Height, got.Seed, original.Width, original.Height, original.Seed)
+			}
+			if !reflect.DeepEqual(got.Terrain, original.Terrain) {
+				t.Error("terrain differs after round trip")
+			}
+			if len(original.MapObjects) == 0 {
+				t.Fatal("generated map has no objects to round trip")
+			}
+			if !reflect.DeepEqual(got.MapObjects, sortObjects(original.MapObjects)) {
+				t.Errorf("objects differ after round trip (%d, want %d)", len(got.MapObjects), len(original.MapObjects))
+			}
+			if !reflect.DeepEqual(got.SpawnZones, original.SpawnZones) {
+				t.Errorf("spawn zones %v, want %v", got.SpawnZones, original.SpawnZones)
+			}
+		})
+	}
+}
+
+// compactTestMap is 4x3 tiles of floor with one object
+func compactTestMap() MapData {
+	m := MapData{Width: 4 * TileSize, Height: 3 * TileSize}
+	for y := 0; y < 3; y++ {
+		m.Terrain = append(m.Terrain, []int{TileOutside, TileFloor, TileFloor, TileFloor})
+	}
+	m.MapObjects = []MapObject{{ID: "3", X: 2, Y: 1}}
+	return m
+}
+
+func TestPackErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(m *MapData)
+		wantErr string
+	}{
+		{"missing row", func(m *MapData) { m.Terrain = m.Terrain[:2] }, "rows"},
+		{"short row", func(m *MapData) { m.Terrain[1] = m.Terrain[1][:3] }, "row 1"},
+		{"terrain too large", func(m *MapData) { m.Terrain[0][0] = 255 }, "out of range"},
+		{"terrain below outside", func(m *MapData) { m.Terrain[0][0] = TileOutside - 1 }, "out of range"},
+		{"non-numeric object", func(m *MapData) { m.MapObjects[0].ID = "crate" }, "can't be packed"},
+		{"object ID too large", func(m *MapData) { m.MapObjects[0].ID = "256" }, "can't be packed"},
+		{"object off the map", func(m *MapData) { m.MapObjects[0].X = 4 }, "off the map"},
+		{"two objects on a tile", func(m *MapData) {
+			m.MapObjects = append(m.MapObjects, MapObject{ID: "4", X: 2, Y: 1})
+		}, "two objects"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := compactTestMap()
+			tt.modify(&m)
+			_, err := Pack(&m)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got error %v, want one mentioning %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUnpackErrors(t *testing.T) {
+	m := compactTestMap()
+	valid, err := Pack(&m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(c *CompactMap)
+	}{
+		{"unknown version", func(c *CompactMap) { c.Version = CompactMapVersion + 1 }},
+		{"bad base64", func(c *CompactMap) { c.Tiles = "not base64!" }},
+		{"not gzip", func(c *CompactMap) { c.Tiles = "AAAA" }},
+		{"size mismatch", func(c *CompactMap) { c.Width += TileSize }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := valid
+			tt.modify(&c)
+			if _, err := c.Unpack(); err == nil {
+				t.Error("got no error")
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+)
+
+// GeneratorConfig shapes a generated map. Chances are percentages.
+// Start from a preset rather than a zero value.
+type GeneratorConfig struct {
+	// Map size in tiles
+	Width  int `json:"width"`
+	Height int `json:"height"`
+
+	// Target floor coverage - how much of the map should be walkable
+	TargetFloorTiles int `json:"targetFloorTiles"`
+	MinFloorTiles    int `json:"minFloorTiles"` // Respawn walkers until reached
+	MaxFloorTiles    int `json:"maxFloorTiles"` // Stop carving past this
+
+	// Walker behavior - lower turn chances give longer corridors
+	ChanceTurn90      int `json:"chanceTurn90"`      // Turn 90 degrees
+	ChanceTurn180     int `json:"chanceTurn180"`     // Turn around, creating enclosed dead ends
+	ChanceSpawnWalker int `json:"chanceSpawnWalker"` // Spawn a child walker each step
+	ChanceDespawnBase int `json:"chanceDespawnBase"` // Base chance to despawn when too many walkers
+	Chance2x2Floor    int `json:"chance2x2Floor"`    // Carve 2x2 instead of 1x1
+	Chance3x3Floor    int `json:"chance3x3Floor"`    // Carve 3x3 mini arenas
+
+	// Walker limits
+	InitialWalkers  int `json:"initialWalkers"`
+	MaxActiveWalker int `json:"maxActiveWalker"`
+	MinActiveWalker int `json:"minActiveWalker"` // Don't despawn below this count
+
+	// Object placement
+	CoverDensity    int `json:"coverDensity"`    // % of open floor tiles that get cover
+	MinCoverSpacing int `json:"minCoverSpacing"` // Tiles between cover objects
+	ChestCount      int `json:"chestCount"`      // Rare chests - only in enclosures
+	LootCount       int `json:"lootCount"`       // Ammo/health pickups
+	MinChestSpacing int `json:"minChestSpacing"` // Tiles between chests
+	MinLootSpacing  int `json:"minLootSpacing"`  // Tiles between loot
+}
+
+// FullMap is the battle royale map for 50-100 players.
+// For 200x200 = 40,000 tiles, ~2000-2500 floors gives good density.
+var FullMap = GeneratorConfig{
+	Width:  200,
+	Height: 200,
+
+	TargetFloorTiles: 2200,
+	MinFloorTiles:    1800,
+	MaxFloorTiles:    2600,
+
+	ChanceTurn90:      12,
+	ChanceTurn180:     4,
+	ChanceSpawnWalker: 8,
+	ChanceDespawnBase: 8,
+	Chance2x2Floor:    55,
+	Chance3x3Floor:    15,
+
+	InitialWalkers:  6,
+	MaxActiveWalker: 12,
+	MinActiveWalker: 3,
+
+	CoverDensity:    2,
+	MinCoverSpacing: 4,
+	ChestCount:      5,
+	LootCount:       15,
+	MinChestSpacing: 40,
+	MinLootSpacing:  15,
+}
+
+// MediumMap suits a few dozen players
+var MediumMap = GeneratorConfig{
+	Width:  140,
+	Height: 140,
+
+	TargetFloorTiles: 1200,
+	MinFloorTiles:    1000,
+	MaxFloorTiles:    1400,
+
+	ChanceTurn90:      14,
+	ChanceTurn180:     4,
+	ChanceSpawnWalker: 8,
+	ChanceDespawnBase: 8,
+	Chance2x2Floor:    55,
+	Chance3x3Floor:    15,
+
+	InitialWalkers:  4,
+	MaxActiveWalker: 8,
+	MinActiveWalker: 2,
+
+	CoverDensity:    2,
+	MinCoverSpacing: 4,
+	ChestCount:      3,
+	LootCount:       8,
+	MinChestSpacing: 30,
+	MinLootSpacing:  12,
+}
+
+// SmallMap is a compact arena for a classroom of up to a dozen players
+var SmallMap = GeneratorConfig{
+	Width:  80,
+	Height: 80,
+
+	TargetFloorTiles: 500,
+	MinFloorTiles:    400,
+	MaxFloorTiles:    600,
+
+	ChanceTurn90:      16,
+	ChanceTurn180:     3,
+	ChanceSpawnWalker: 6,
+	ChanceDespawnBase: 8,
+	Chance2x2Floor:    55,
+	Chance3x3Floor:    20,
+
+	InitialWalkers:  3,
+	MaxActiveWalker: 6,
+	MinActiveWalker: 2,
+
+	CoverDensity:    3,
+	MinCoverSpacing: 3,
+	ChestCount:      2,
+	LootCount:       4,
+	MinChestSpacing: 20,
+	MinLootSpacing:  10,
+}
+
+// Map size limits in tiles. Generation leaves a 10-tile margin on each
+// side, and 400x400 is already four times the full map.
+const (
+	MinMapSize = 40
+	MaxMapSize = 400
+)
+
+// presets are the named configs rooms can choose from
+var presets = map[string]GeneratorConfig{
+	"small":  SmallMap,
+	"medium": MediumMap,
+	"full":   FullMap,
+}
+
+// Preset returns a named generator config
+func Preset(name string) (GeneratorConfig, bool) {
+	cfg, exists := presets[name]
+	return cfg, exists
+}
+
+// PresetNames lists the preset names in alphabetical order
+func PresetNames() []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PresetFor picks the preset sized for a number of players
+func PresetFor(players int) string {
+	switch {
+	case players <= 12:
+		return "small"
+	case players <= 40:
+		return "medium"
+	}
+	return "full"
+}
+
+// Validate checks the config can generate a playable map
+func (c GeneratorConfig) Validate() error {
+	// Walkers keep a 5-tile border
+	carvable := (c.Width - 10) * (c.Height - 10)
+
+	switch {
+	case c.Width < MinMapSize || c.Width > MaxMapSize || c.Height < MinMapSize || c.Height > MaxMapSize:
+		return fmt.Errorf("width and height must be between %d and %d tiles", MinMapSize, MaxMapSize)
+	case c.TargetFloorTiles < 100 || c.TargetFloorTiles > carvable/2:
+		return fmt.Errorf("targetFloorTiles must be between 100 and %d", carvable/2)
+	case c.MinFloorTiles < 1 || c.MinFloorTiles > c.TargetFloorTiles:
+		return fmt.Errorf("minFloorTiles must be between 1 and targetFloorTiles")
+	case c.MaxFloorTiles < c.TargetFloorTiles || c.MaxFloorTiles > carvable:
+		return fmt.Errorf("maxFloorTiles must be between targetFloorTiles and %d", carvable)
+	case !isChance(c.ChanceTurn90) || !isChance(c.ChanceTurn180) || c.ChanceTurn90+c.ChanceTurn180 > 100:
+		return fmt.Errorf("chanceTurn90 and chanceTurn180 must be percentages adding up to at most 100")
+	case !isChance(c.ChanceSpawnWalker) || !isChance(c.ChanceDespawnBase):
+		return fmt.Errorf("chanceSpawnWalker and chanceDespawnBase must be between 0 and 100")
+	case !isChance(c.Chance2x2Floor) || !isChance(c.Chance3x3Floor) || c.Chance2x2Floor+c.Chance3x3Floor > 100:
+		return fmt.Errorf("chance2x2Floor and chance3x3Floor must be percentages adding up to at most 100")
+	case c.MaxActiveWalker < 1 || c.MaxActiveWalker > 100:
+		return fmt.Errorf("maxActiveWalker must be between 1 and 100")
+	case c.InitialWalkers < 1 || c.InitialWalkers > c.MaxActiveWalker:
+		return fmt.Errorf("initialWalkers must be between 1 and maxActiveWalker")
+	case c.MinActiveWalker < 0 || c.MinActiveWalker > c.MaxActiveWalker:
+		return fmt.Errorf("minActiveWalker must be between 0 and maxActiveWalker")
+	case c.CoverDensity < 0 || c.CoverDensity > 20:
+		return fmt.Errorf("coverDensity must be between 0 and 20")
+	case c.MinCoverSpacing < 1 || c.MinChestSpacing < 1 || c.MinLootSpacing < 1:
+		return fmt.Errorf("minCoverSpacing, minChestSpacing and minLootSpacing must be at least 1")
+	case c.ChestCount < 0 || c.ChestCount > 50:
+		return fmt.Errorf("chestCount must be between 0 and 50")
+	case c.LootCount < 0 || c.LootCount > 200:
+		return fmt.Errorf("lootCount must be between 0 and 200")
+	}
+	return nil
+}
+
+// isChance reports whether n is a valid percentage
+func isChance(n int) bool {
+	return n >= 0 && n <= 100
+}
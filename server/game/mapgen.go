@@ -19,42 +19,15 @@ import (
 // =============================================================================
 
 // -----------------------------------------------------------------------------
-// CONFIGURATION CONSTANTS
-// These control the "feel" of generated maps
+// CONFIGURATION
+// The "feel" of generated maps comes from a GeneratorConfig (generator.go)
 // -----------------------------------------------------------------------------
 const (
-	// Target floor coverage - how much of the map should be walkable
-	// For 200x200 = 40,000 tiles, we want ~2000-2500 floors for good density
-	TargetFloorTiles = 2200
-	MinFloorTiles    = 1800
-	MaxFloorTiles    = 2600
-
-	// Walker behavior probabilities (percentages)
-	// Lower turn chances = longer corridors
-	ChanceTurn90       = 12 // % chance to turn 90 degrees (reduced for longer corridors)
-	ChanceTurn180      = 4  // % chance to turn 180 degrees (rare, creates enclosed dead ends)
-	ChanceSpawnWalker  = 8  // % chance to spawn a child walker each step
-	ChanceDespawnBase  = 8  // Base % chance to despawn when too many walkers
-	Chance2x2Floor     = 55 // % chance to carve 2x2 (increased for denser combat areas)
-	Chance3x3Floor     = 15 // % chance to carve 3x3 (more mini arenas for combat)
-
-	// Walker limits
-	InitialWalkers  = 6  // Starting walker count
-	MaxActiveWalker = 12 // Maximum concurrent walkers
-	MinActiveWalker = 3  // Don't despawn below this count
-
-	// Object placement
-	CoverDensity      = 2   // % of floor tiles that get cover objects
-	MinCoverSpacing   = 4   // Minimum tiles between cover objects
-	ChestCount        = 5   // Rare chests - only in enclosures
-	LootCount         = 15  // Number of ammo/health pickups
-	MinChestSpacing   = 40  // Large spacing - chests are rare finds
-	MinLootSpacing    = 15  // Minimum tiles between loot
-
 	// Spawn zones
 	SpawnZoneCount   = 12 // Number of spawn zones around perimeter
 	MinSpawnDistance = 40 // Minimum distance from center for spawns
 	SpawnZoneBuffer  = 10 // Tiles from map edge for spawn zone
+	SpawnZoneRadius  = 4  // Tiles around a zone's center players spawn in
 )
 
 // Tile types for the generation grid
@@ -96,32 +69,42 @@ type Point struct {
 	X, Y int
 }
 
+// MaxSeed is the largest map seed, kept within the integers a JavaScript
+// number holds exactly so clients can echo seeds back unchanged
+const MaxSeed = 1<<53 - 1
+
+// NewSeed returns a random map seed
+func NewSeed() int64 {
+	return rand.Int63n(MaxSeed + 1)
+}
+
 // -----------------------------------------------------------------------------
 // MAIN GENERATION FUNCTION
 // Orchestrates all phases of map generation
+// The same config and seed always produce the same map
 // -----------------------------------------------------------------------------
-func GenerateMap() MapData {
+func Generate(cfg GeneratorConfig, seed int64) MapData {
+	rng := rand.New(rand.NewSource(seed))
+
 	mapData := MapData{
-		Width:      MapSize * 16, // Convert to pixel dimensions
-		Height:     MapSize * 16,
+		Width:      cfg.Width * TileSize, // Convert to pixel dimensions
+		Height:     cfg.Height * TileSize,
 		MapObjects: []MapObject{},
-		Terrain:    [MapSize][MapSize]int{},
+		Terrain:    newGrid(cfg.Width, cfg.Height),
+		Seed:       seed,
 	}
 
 	// Initialize terrain texture variation (cosmetic only)
-	generateTerrainTexture(&mapData)
+	generateTerrainTexture(rng, &mapData)
 
 	// Create the carving grid (0 = wall, 1 = floor)
-	grid := make([][]int, MapSize)
-	for i := range grid {
-		grid[i] = make([]int, MapSize)
-	}
+	grid := newGrid(cfg.Width, cfg.Height)
 
 	// =========================================================================
 	// PHASE 1: MULTI-WALKER FLOOR CARVING
 	// This is the core Nuclear Throne algorithm
 	// =========================================================================
-	chestLocations, lootLocations := carveWithWalkers(grid)
+	chestLocations, lootLocations := carveWithWalkers(rng, cfg, grid)
 
 	// =========================================================================
 	// PHASE 2: ENSURE CONNECTIVITY
@@ -134,14 +117,14 @@ func GenerateMap() MapData {
 	// Add tactical cover points throughout the map
 	// =========================================================================
 	occupiedTiles := make(map[string]bool)
-	placeCoverObjects(&mapData, grid, occupiedTiles)
+	placeCoverObjects(rng, cfg, &mapData, grid, occupiedTiles)
 
 	// =========================================================================
 	// PHASE 4: CHEST & LOOT PLACEMENT
 	// Place chests at dead ends, loot at walker death points
 	// =========================================================================
-	placeChests(&mapData, grid, chestLocations, occupiedTiles)
-	placeLoot(&mapData, grid, lootLocations, occupiedTiles)
+	placeChests(rng, cfg, &mapData, grid, chestLocations, occupiedTiles)
+	placeLoot(rng, cfg, &mapData, grid, lootLocations, occupiedTiles)
 
 	// =========================================================================
 	// PHASE 5: BUILD WALL OBJECTS
@@ -156,29 +139,51 @@ func GenerateMap() MapData {
 	// =========================================================================
 	markOutsideTiles(grid, &mapData)
 
+	// =========================================================================
+	// PHASE 7: SPAWN ZONES
+	// One zone per sector around the center, as far out as the cave reaches
+	// =========================================================================
+	placeSpawnZones(&mapData, grid, occupiedTiles)
+
 	return mapData
 }
 
+// newGrid allocates a height x width tile grid
+func newGrid(width, height int) [][]int {
+	grid := make([][]int, height)
+	for y := range grid {
+		grid[y] = make([]int, width)
+	}
+	return grid
+}
+
+// inGrid reports whether (x, y) is a tile of the grid
+func inGrid(grid [][]int, x, y int) bool {
+	return y >= 0 && y < len(grid) && x >= 0 && x < len(grid[y])
+}
+
 // -----------------------------------------------------------------------------
 // PHASE 1: MULTI-WALKER CARVING SYSTEM
 // The heart of Nuclear Throne-style generation
 // -----------------------------------------------------------------------------
-func carveWithWalkers(grid [][]int) ([]Point, []Point) {
+func carveWithWalkers(rng *rand.Rand, cfg GeneratorConfig, grid [][]int) ([]Point, []Point) {
+	width, height := len(grid[0]), len(grid)
+
 	var chestLocations []Point  // Marked at 180-degree turns
 	var lootLocations []Point   // Marked at walker death points
 
 	// Initialize walkers at center, facing random directions
-	center := MapSize / 2
-	walkers := make([]*Walker, 0, MaxActiveWalker)
+	centerX, centerY := width/2, height/2
+	walkers := make([]*Walker, 0, cfg.MaxActiveWalker)
 
-	for i := 0; i < InitialWalkers; i++ {
+	for i := 0; i < cfg.InitialWalkers; i++ {
 		// Offset each initial walker slightly from center
-		offsetX := rand.Intn(5) - 2
-		offsetY := rand.Intn(5) - 2
+		offsetX := rng.Intn(5) - 2
+		offsetY := rng.Intn(5) - 2
 		walkers = append(walkers, &Walker{
-			X:         center + offsetX,
-			Y:         center + offsetY,
-			Direction: rand.Intn(4),
+			X:         centerX + offsetX,
+			Y:         centerY + offsetY,
+			Direction: rng.Intn(4),
 			Steps:     0,
 			Active:    true,
 		})
@@ -187,7 +192,7 @@ func carveWithWalkers(grid [][]int) ([]Point, []Point) {
 	// Carve the starting area (3x3 around center)
 	for dy := -1; dy <= 1; dy++ {
 		for dx := -1; dx <= 1; dx++ {
-			grid[center+dy][center+dx] = TileFloor
+			grid[centerY+dy][centerX+dx] = TileFloor
 		}
 	}
 
@@ -195,9 +200,9 @@ func carveWithWalkers(grid [][]int) ([]Point, []Point) {
 
 	// Main carving loop - continue until we hit target floor count
 	iteration := 0
-	maxIterations := TargetFloorTiles * 3 // Safety limit
+	maxIterations := cfg.TargetFloorTiles * 3 // Safety limit
 
-	for floorCount < TargetFloorTiles && iteration < maxIterations {
+	for floorCount < cfg.TargetFloorTiles && iteration < maxIterations {
 		iteration++
 
 		// Process each active walker
@@ -209,15 +214,15 @@ func carveWithWalkers(grid [][]int) ([]Point, []Point) {
 			// ------------------------------------------------------------------
 			// STEP 1: Decide on direction change
 			// ------------------------------------------------------------------
-			turnRoll := rand.Intn(100)
+			turnRoll := rng.Intn(100)
 
-			if turnRoll < ChanceTurn180 {
+			if turnRoll < cfg.ChanceTurn180 {
 				// 180-degree turn - mark this as a potential chest location!
 				walker.Direction = (walker.Direction + 2) % 4
 				chestLocations = append(chestLocations, Point{walker.X, walker.Y})
-			} else if turnRoll < ChanceTurn180+ChanceTurn90 {
+			} else if turnRoll < cfg.ChanceTurn180+cfg.ChanceTurn90 {
 				// 90-degree turn (randomly left or right)
-				if rand.Intn(2) == 0 {
+				if rng.Intn(2) == 0 {
 					walker.Direction = (walker.Direction + 1) % 4
 				} else {
 					walker.Direction = (walker.Direction + 3) % 4
@@ -232,7 +237,7 @@ func carveWithWalkers(grid [][]int) ([]Point, []Point) {
 			newY := walker.Y + dirVectors[walker.Direction][1]
 
 			// Keep walker within bounds (leave 5-tile border)
-			if newX < 5 || newX >= MapSize-5 || newY < 5 || newY >= MapSize-5 {
+			if newX < 5 || newX >= width-5 || newY < 5 || newY >= height-5 {
 				// Hit boundary - turn around
 				walker.Direction = (walker.Direction + 2) % 4
 				continue
@@ -245,18 +250,18 @@ func carveWithWalkers(grid [][]int) ([]Point, []Point) {
 			// ------------------------------------------------------------------
 			// STEP 3: Carve floor tiles (1x1, 2x2, or 3x3)
 			// ------------------------------------------------------------------
-			floorCount += carveFloorArea(grid, walker.X, walker.Y)
+			floorCount += carveFloorArea(rng, cfg, grid, walker.X, walker.Y)
 
 			// ------------------------------------------------------------------
 			// STEP 4: Possibly spawn a child walker
 			// ------------------------------------------------------------------
 			activeCount := countActiveWalkers(walkers)
-			if activeCount < MaxActiveWalker && rand.Intn(100) < ChanceSpawnWalker {
+			if activeCount < cfg.MaxActiveWalker && rng.Intn(100) < cfg.ChanceSpawnWalker {
 				// Spawn new walker at current position, random direction
 				newWalker := &Walker{
 					X:         walker.X,
 					Y:         walker.Y,
-					Direction: rand.Intn(4),
+					Direction: rng.Intn(4),
 					Steps:     0,
 					Active:    true,
 				}
@@ -266,10 +271,10 @@ func carveWithWalkers(grid [][]int) ([]Point, []Point) {
 			// ------------------------------------------------------------------
 			// STEP 5: Possibly despawn this walker (if too many)
 			// ------------------------------------------------------------------
-			if activeCount > MinActiveWalker {
+			if activeCount > cfg.MinActiveWalker {
 				// Despawn chance increases with more walkers
-				despawnChance := ChanceDespawnBase + (activeCount-MinActiveWalker)*5
-				if rand.Intn(100) < despawnChance {
+				despawnChance := cfg.ChanceDespawnBase + (activeCount-cfg.MinActiveWalker)*5
+				if rng.Intn(100) < despawnChance {
 					walker.Active = false
 					// Mark death location for loot
 					lootLocations = append(lootLocations, Point{walker.X, walker.Y})
@@ -278,16 +283,16 @@ func carveWithWalkers(grid [][]int) ([]Point, []Point) {
 		}
 
 		// If all walkers dead and not enough floors, spawn a new one
-		if countActiveWalkers(walkers) == 0 && floorCount < MinFloorTiles {
+		if countActiveWalkers(walkers) == 0 && floorCount < cfg.MinFloorTiles {
 			// Find a random floor tile to spawn new walker
 			for attempts := 0; attempts < 100; attempts++ {
-				x := 10 + rand.Intn(MapSize-20)
-				y := 10 + rand.Intn(MapSize-20)
+				x := 10 + rng.Intn(width-20)
+				y := 10 + rng.Intn(height-20)
 				if grid[y][x] == TileFloor {
 					walkers = append(walkers, &Walker{
 						X:         x,
 						Y:         y,
-						Direction: rand.Intn(4),
+						Direction: rng.Intn(4),
 						Steps:     0,
 						Active:    true,
 					})
@@ -297,7 +302,7 @@ func carveWithWalkers(grid [][]int) ([]Point, []Point) {
 		}
 
 		// Safety check - stop if we have way too many floors
-		if floorCount >= MaxFloorTiles {
+		if floorCount >= cfg.MaxFloorTiles {
 			break
 		}
 	}
@@ -306,16 +311,18 @@ func carveWithWalkers(grid [][]int) ([]Point, []Point) {
 }
 
 // carveFloorArea carves floor tiles at position, returns count of new tiles carved
-func carveFloorArea(grid [][]int, x, y int) int {
+func carveFloorArea(rng *rand.Rand, cfg GeneratorConfig, grid [][]int, x, y int) int {
+	width, height := len(grid[0]), len(grid)
+
 	carved := 0
 
 	// Determine carve size
-	sizeRoll := rand.Intn(100)
+	sizeRoll := rng.Intn(100)
 	var size int
 
-	if sizeRoll < Chance3x3Floor {
+	if sizeRoll < cfg.Chance3x3Floor {
 		size = 3 // 3x3 mini arena
-	} else if sizeRoll < Chance3x3Floor+Chance2x2Floor {
+	} else if sizeRoll < cfg.Chance3x3Floor+cfg.Chance2x2Floor {
 		size = 2 // 2x2 open area (Nuclear Throne desert style)
 	} else {
 		size = 1 // 1x1 standard
@@ -326,7 +333,7 @@ func carveFloorArea(grid [][]int, x, y int) int {
 	for dy := -halfSize; dy <= halfSize; dy++ {
 		for dx := -halfSize; dx <= halfSize; dx++ {
 			nx, ny := x+dx, y+dy
-			if nx >= 2 && nx < MapSize-2 && ny >= 2 && ny < MapSize-2 {
+			if nx >= 2 && nx < width-2 && ny >= 2 && ny < height-2 {
 				if grid[ny][nx] == TileWall {
 					grid[ny][nx] = TileFloor
 					carved++
@@ -355,16 +362,18 @@ func countActiveWalkers(walkers []*Walker) int {
 // Optimized: simply removes disconnected floor tiles instead of tunneling
 // -----------------------------------------------------------------------------
 func ensureConnectivity(grid [][]int) {
+	width, height := len(grid[0]), len(grid)
+
 	// Find the center floor tile to start flood fill
-	center := MapSize / 2
+	centerX, centerY := width/2, height/2
 	startX, startY := -1, -1
 
 	// Search outward from center for a floor tile
-	for radius := 0; radius < MapSize/2 && startX == -1; radius++ {
+	for radius := 0; radius < max(width, height)/2 && startX == -1; radius++ {
 		for dy := -radius; dy <= radius; dy++ {
 			for dx := -radius; dx <= radius; dx++ {
-				x, y := center+dx, center+dy
-				if x >= 0 && x < MapSize && y >= 0 && y < MapSize {
+				x, y := centerX+dx, centerY+dy
+				if inGrid(grid, x, y) {
 					if grid[y][x] == TileFloor {
 						startX, startY = x, y
 						break
@@ -382,17 +391,17 @@ func ensureConnectivity(grid [][]int) {
 	}
 
 	// Flood fill from start point using 2D array (faster than map)
-	visited := make([][]bool, MapSize)
+	visited := make([][]bool, height)
 	for i := range visited {
-		visited[i] = make([]bool, MapSize)
+		visited[i] = make([]bool, width)
 	}
 
 	floodFill(grid, visited, startX, startY)
 
 	// Any floor tile not visited is disconnected - convert to wall
 	// This is faster than trying to connect them
-	for y := 0; y < MapSize; y++ {
-		for x := 0; x < MapSize; x++ {
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
 			if grid[y][x] == TileFloor && !visited[y][x] {
 				grid[y][x] = TileWall
 			}
@@ -409,7 +418,7 @@ func floodFill(grid [][]int, visited [][]bool, startX, startY int) {
 		p := stack[len(stack)-1]
 		stack = stack[:len(stack)-1]
 
-		if p.X < 0 || p.X >= MapSize || p.Y < 0 || p.Y >= MapSize {
+		if !inGrid(grid, p.X, p.Y) {
 			continue
 		}
 		if visited[p.Y][p.X] || grid[p.Y][p.X] != TileFloor {
@@ -430,11 +439,13 @@ func floodFill(grid [][]int, visited [][]bool, startX, startY int) {
 // PHASE 3: COVER & OBSTACLE PLACEMENT
 // Adds tactical cover throughout the map for combat
 // -----------------------------------------------------------------------------
-func placeCoverObjects(mapData *MapData, grid [][]int, occupied map[string]bool) {
+func placeCoverObjects(rng *rand.Rand, cfg GeneratorConfig, mapData *MapData, grid [][]int, occupied map[string]bool) {
+	width, height := len(grid[0]), len(grid)
+
 	// Collect all valid floor positions
 	var floorTiles []Point
-	for y := 5; y < MapSize-5; y++ {
-		for x := 5; x < MapSize-5; x++ {
+	for y := 5; y < height-5; y++ {
+		for x := 5; x < width-5; x++ {
 			if grid[y][x] == TileFloor {
 				// Check if has enough floor neighbors (not in narrow corridor)
 				floorNeighbors := countFloorNeighbors(grid, x, y)
@@ -446,11 +457,11 @@ func placeCoverObjects(mapData *MapData, grid [][]int, occupied map[string]bool)
 	}
 
 	// Calculate target cover count
-	targetCover := len(floorTiles) * CoverDensity / 100
+	targetCover := len(floorTiles) * cfg.CoverDensity / 100
 	placed := 0
 
 	// Shuffle floor tiles for random placement
-	rand.Shuffle(len(floorTiles), func(i, j int) {
+	rng.Shuffle(len(floorTiles), func(i, j int) {
 		floorTiles[i], floorTiles[j] = floorTiles[j], floorTiles[i]
 	})
 
@@ -460,7 +471,7 @@ func placeCoverObjects(mapData *MapData, grid [][]int, occupied map[string]bool)
 		}
 
 		// Check minimum spacing from other cover
-		if !checkSpacing(occupied, tile.X, tile.Y, MinCoverSpacing) {
+		if !checkSpacing(occupied, tile.X, tile.Y, cfg.MinCoverSpacing) {
 			continue
 		}
 
@@ -486,7 +497,7 @@ func countFloorNeighbors(grid [][]int, x, y int) int {
 				continue
 			}
 			nx, ny := x+dx, y+dy
-			if nx >= 0 && nx < MapSize && ny >= 0 && ny < MapSize {
+			if inGrid(grid, nx, ny) {
 				if grid[ny][nx] == TileFloor {
 					count++
 				}
@@ -514,12 +525,14 @@ func checkSpacing(occupied map[string]bool, x, y, minDist int) bool {
 // Chests ONLY in enclosures (dead ends with limited exits) - rare finds!
 // Loot at walker death points
 // -----------------------------------------------------------------------------
-func placeChests(mapData *MapData, grid [][]int, locations []Point, occupied map[string]bool) {
+func placeChests(rng *rand.Rand, cfg GeneratorConfig, mapData *MapData, grid [][]int, locations []Point, occupied map[string]bool) {
+	width, height := len(grid[0]), len(grid)
+
 	// Filter locations to only include true enclosures
 	var enclosedLocations []Point
 
 	for _, loc := range locations {
-		if loc.X < 5 || loc.X >= MapSize-5 || loc.Y < 5 || loc.Y >= MapSize-5 {
+		if loc.X < 5 || loc.X >= width-5 || loc.Y < 5 || loc.Y >= height-5 {
 			continue
 		}
 		if grid[loc.Y][loc.X] != TileFloor {
@@ -537,12 +550,12 @@ func placeChests(mapData *MapData, grid [][]int, locations []Point, occupied map
 	}
 
 	// If we don't have enough enclosed locations, find some manually
-	if len(enclosedLocations) < ChestCount {
-		enclosedLocations = append(enclosedLocations, findEnclosedSpots(grid, occupied, ChestCount-len(enclosedLocations))...)
+	if len(enclosedLocations) < cfg.ChestCount {
+		enclosedLocations = append(enclosedLocations, findEnclosedSpots(grid, occupied, cfg.ChestCount-len(enclosedLocations))...)
 	}
 
 	// Filter and space out chest locations
-	validLocations := filterLocations(grid, enclosedLocations, occupied, MinChestSpacing, ChestCount)
+	validLocations := filterLocations(rng, grid, enclosedLocations, occupied, cfg.MinChestSpacing, cfg.ChestCount)
 
 	for _, loc := range validLocations {
 		key := fmt.Sprintf("%d,%d", loc.X, loc.Y)
@@ -567,7 +580,7 @@ func calculateEnclosureScore(grid [][]int, x, y int) int {
 				continue
 			}
 			nx, ny := x+dx, y+dy
-			if nx < 0 || nx >= MapSize || ny < 0 || ny >= MapSize {
+			if !inGrid(grid, nx, ny) {
 				wallCount++ // Out of bounds counts as wall
 				continue
 			}
@@ -582,6 +595,8 @@ func calculateEnclosureScore(grid [][]int, x, y int) int {
 
 // findEnclosedSpots searches the map for naturally enclosed areas
 func findEnclosedSpots(grid [][]int, occupied map[string]bool, count int) []Point {
+	width, height := len(grid[0]), len(grid)
+
 	var spots []Point
 	type scoredPoint struct {
 		point Point
@@ -591,8 +606,8 @@ func findEnclosedSpots(grid [][]int, occupied map[string]bool, count int) []Poin
 
 	// Scan the map for enclosed spots (sample to avoid scanning everything)
 	step := 3 // Check every 3rd tile for speed
-	for y := 10; y < MapSize-10; y += step {
-		for x := 10; x < MapSize-10; x += step {
+	for y := 10; y < height-10; y += step {
+		for x := 10; x < width-10; x += step {
 			if grid[y][x] != TileFloor {
 				continue
 			}
@@ -635,9 +650,9 @@ func findEnclosedSpots(grid [][]int, occupied map[string]bool, count int) []Poin
 	return spots
 }
 
-func placeLoot(mapData *MapData, grid [][]int, locations []Point, occupied map[string]bool) {
+func placeLoot(rng *rand.Rand, cfg GeneratorConfig, mapData *MapData, grid [][]int, locations []Point, occupied map[string]bool) {
 	// Filter and space out loot locations
-	validLocations := filterLocations(grid, locations, occupied, MinLootSpacing, LootCount)
+	validLocations := filterLocations(rng, grid, locations, occupied, cfg.MinLootSpacing, cfg.LootCount)
 
 	for _, loc := range validLocations {
 		key := fmt.Sprintf("%d,%d", loc.X, loc.Y)
@@ -645,7 +660,7 @@ func placeLoot(mapData *MapData, grid [][]int, locations []Point, occupied map[s
 
 		// Alternate between different loot types (11 = ammo, 12 = health)
 		lootID := "11"
-		if rand.Intn(2) == 0 {
+		if rng.Intn(2) == 0 {
 			lootID = "12"
 		}
 
@@ -658,11 +673,13 @@ func placeLoot(mapData *MapData, grid [][]int, locations []Point, occupied map[s
 }
 
 // filterLocations filters points by validity, spacing, and count
-func filterLocations(grid [][]int, locations []Point, occupied map[string]bool, minSpacing, maxCount int) []Point {
+func filterLocations(rng *rand.Rand, grid [][]int, locations []Point, occupied map[string]bool, minSpacing, maxCount int) []Point {
+	width, height := len(grid[0]), len(grid)
+
 	var result []Point
 
 	// Shuffle for randomness
-	rand.Shuffle(len(locations), func(i, j int) {
+	rng.Shuffle(len(locations), func(i, j int) {
 		locations[i], locations[j] = locations[j], locations[i]
 	})
 
@@ -672,7 +689,7 @@ func filterLocations(grid [][]int, locations []Point, occupied map[string]bool,
 		}
 
 		// Must be on floor
-		if loc.X < 5 || loc.X >= MapSize-5 || loc.Y < 5 || loc.Y >= MapSize-5 {
+		if loc.X < 5 || loc.X >= width-5 || loc.Y < 5 || loc.Y >= height-5 {
 			continue
 		}
 		if grid[loc.Y][loc.X] != TileFloor {
@@ -707,8 +724,8 @@ func filterLocations(grid [][]int, locations []Point, occupied map[string]bool,
 	attempts := 0
 	for len(result) < maxCount && attempts < maxAttempts {
 		attempts++
-		x := 10 + rand.Intn(MapSize-20)
-		y := 10 + rand.Intn(MapSize-20)
+		x := 10 + rng.Intn(width-20)
+		y := 10 + rng.Intn(height-20)
 
 		if grid[y][x] != TileFloor {
 			continue
@@ -743,8 +760,10 @@ func filterLocations(grid [][]int, locations []Point, occupied map[string]bool,
 // Converts grid boundaries to renderable wall objects
 // -----------------------------------------------------------------------------
 func buildWallObjects(mapData *MapData, grid [][]int, occupied map[string]bool) {
-	for y := 0; y < MapSize; y++ {
-		for x := 0; x < MapSize; x++ {
+	width, height := len(grid[0]), len(grid)
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
 			// Skip floor tiles
 			if grid[y][x] == TileFloor {
 				continue
@@ -755,7 +774,7 @@ func buildWallObjects(mapData *MapData, grid [][]int, occupied map[string]bool)
 			for dy := -1; dy <= 1; dy++ {
 				for dx := -1; dx <= 1; dx++ {
 					ny, nx := y+dy, x+dx
-					if ny >= 0 && ny < MapSize && nx >= 0 && nx < MapSize {
+					if inGrid(grid, nx, ny) {
 						if grid[ny][nx] == TileFloor {
 							adjacentToFloor = true
 							break
@@ -777,9 +796,9 @@ func buildWallObjects(mapData *MapData, grid [][]int, occupied map[string]bool)
 
 			// Determine wall type based on adjacent floor direction
 			left := x > 0 && grid[y][x-1] == TileFloor
-			right := x < MapSize-1 && grid[y][x+1] == TileFloor
+			right := x < width-1 && grid[y][x+1] == TileFloor
 			up := y > 0 && grid[y-1][x] == TileFloor
-			down := y < MapSize-1 && grid[y+1][x] == TileFloor
+			down := y < height-1 && grid[y+1][x] == TileFloor
 
 			// Wall type: "7" = default, "8" = horizontal-facing
 			wallType := "7"
@@ -800,14 +819,14 @@ func buildWallObjects(mapData *MapData, grid [][]int, occupied map[string]bool)
 // TERRAIN TEXTURE GENERATION
 // Cosmetic variation for the ground tiles
 // -----------------------------------------------------------------------------
-func generateTerrainTexture(mapData *MapData) {
-	for y := 0; y < MapSize; y++ {
-		for x := 0; x < MapSize; x++ {
+func generateTerrainTexture(rng *rand.Rand, mapData *MapData) {
+	for y := range mapData.Terrain {
+		for x := range mapData.Terrain[y] {
 			// 90% base tile, 10% variation tiles
-			if rand.Float64() < 0.9 {
+			if rng.Float64() < 0.9 {
 				mapData.Terrain[y][x] = 0
 			} else {
-				mapData.Terrain[y][x] = 1 + rand.Intn(6)
+				mapData.Terrain[y][x] = 1 + rng.Intn(6)
 			}
 		}
 	}
@@ -819,20 +838,22 @@ func generateTerrainTexture(mapData *MapData) {
 // This prevents players from spawning in unreachable locations
 // -----------------------------------------------------------------------------
 func markOutsideTiles(grid [][]int, mapData *MapData) {
+	width, height := len(grid[0]), len(grid)
+
 	// First, find all floor tiles reachable from the center
-	reachable := make([][]bool, MapSize)
+	reachable := make([][]bool, height)
 	for i := range reachable {
-		reachable[i] = make([]bool, MapSize)
+		reachable[i] = make([]bool, width)
 	}
 
 	// Find starting point near center
-	center := MapSize / 2
+	centerX, centerY := width/2, height/2
 	startX, startY := -1, -1
-	for radius := 0; radius < MapSize/2 && startX == -1; radius++ {
+	for radius := 0; radius < max(width, height)/2 && startX == -1; radius++ {
 		for dy := -radius; dy <= radius; dy++ {
 			for dx := -radius; dx <= radius; dx++ {
-				x, y := center+dx, center+dy
-				if x >= 0 && x < MapSize && y >= 0 && y < MapSize {
+				x, y := centerX+dx, centerY+dy
+				if inGrid(grid, x, y) {
 					if grid[y][x] == TileFloor {
 						startX, startY = x, y
 						break
@@ -852,7 +873,7 @@ func markOutsideTiles(grid [][]int, mapData *MapData) {
 			p := stack[len(stack)-1]
 			stack = stack[:len(stack)-1]
 
-			if p.X < 0 || p.X >= MapSize || p.Y < 0 || p.Y >= MapSize {
+			if !inGrid(grid, p.X, p.Y) {
 				continue
 			}
 			if reachable[p.Y][p.X] || grid[p.Y][p.X] != TileFloor {
@@ -870,11 +891,76 @@ func markOutsideTiles(grid [][]int, mapData *MapData) {
 
 	// Set terrain = -1 for ANY tile that is not a reachable floor
 	// This covers: exterior walls, interior walls, and enclosed floor pockets
-	for y := 0; y < MapSize; y++ {
-		for x := 0; x < MapSize; x++ {
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
 			if !reachable[y][x] {
 				mapData.Terrain[y][x] = TileOutside
 			}
 		}
 	}
 }
+
+// -----------------------------------------------------------------------------
+// PHASE 7: SPAWN ZONES
+// The cave is split into SpawnZoneCount pie slices around its middle, and
+// each slice gets a zone on its outermost open floor. Slices the cave barely
+// reaches into get none.
+// -----------------------------------------------------------------------------
+func placeSpawnZones(mapData *MapData, grid [][]int, occupied map[string]bool) {
+	width, height := len(grid[0]), len(grid)
+
+	// Walkers drift, so center on the reachable floor rather than the map
+	sumX, sumY, floors := 0, 0, 0
+	for y, row := range mapData.Terrain {
+		for x, value := range row {
+			if value != TileOutside {
+				sumX, sumY, floors = sumX+x, sumY+y, floors+1
+			}
+		}
+	}
+	if floors == 0 {
+		mapData.SpawnZones = []SpawnZone{}
+		return
+	}
+	centerX, centerY := sumX/floors, sumY/floors
+
+	type candidate struct {
+		tile  Point
+		dist  float64
+		found bool
+	}
+	best := make([]candidate, SpawnZoneCount)
+	farthest := 0.0
+
+	for y := SpawnZoneBuffer; y < height-SpawnZoneBuffer; y++ {
+		for x := SpawnZoneBuffer; x < width-SpawnZoneBuffer; x++ {
+			if mapData.Terrain[y][x] == TileOutside || occupied[fmt.Sprintf("%d,%d", x, y)] {
+				continue
+			}
+			// Zone centers sit in the open, not in narrow corridors
+			if countFloorNeighbors(grid, x, y) < 5 {
+				continue
+			}
+
+			dx, dy := float64(x-centerX), float64(y-centerY)
+			dist := math.Hypot(dx, dy)
+			angle := math.Atan2(dy, dx) + math.Pi
+			sector := int(angle/(2*math.Pi)*SpawnZoneCount) % SpawnZoneCount
+
+			if dist > best[sector].dist {
+				best[sector] = candidate{tile: Point{x, y}, dist: dist, found: true}
+			}
+			farthest = math.Max(farthest, dist)
+		}
+	}
+
+	// Small caves can't reach MinSpawnDistance, so settle for halfway out
+	minDist := math.Min(MinSpawnDistance, farthest/2)
+
+	mapData.SpawnZones = []SpawnZone{}
+	for _, c := range best {
+		if c.found && c.dist >= minDist {
+			mapData.SpawnZones = append(mapData.SpawnZones, SpawnZone{X: c.tile.X, Y: c.tile.Y, Radius: SpawnZoneRadius})
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package game
+
+import "math"
+
+// =============================================================================
+// SERVER-SIDE MOVEMENT VALIDATION
+// Clients report their own positions; the server checks each move against
+// a speed limit and the map so players can't teleport or walk through walls.
+// =============================================================================
+
+const (
+	PlayerSpeed = 150.0 // Pixels per second on each axis, as in MainScene.ts
+
+	// MaxPlayerSpeed allows for diagonal movement plus some timing jitter
+	MaxPlayerSpeed = PlayerSpeed * math.Sqrt2 * 1.2
+
+	MoveSlack       = 24.0 // Pixels of leeway on every update for network jitter
+	MaxMoveInterval = 0.5  // Seconds of movement a single update may cover
+	moveStep        = TileSize / 4
+)
+
+// IsWalkableAt reports whether a player may stand at the pixel position (x, y)
+func (cm *CollisionMap) IsWalkableAt(x, y float64) bool {
+	tx, ty := int(math.Floor(x/TileSize)), int(math.Floor(y/TileSize))
+	return !cm.IsSolid(tx, ty) && !cm.outside[ty*cm.cols+tx]
+}
+
+// ValidateMove checks a move from (fromX, fromY) to (toX, toY) made over
+// dt seconds. It returns the furthest legal position along the way and
+// whether that is the requested target.
+func (cm *CollisionMap) ValidateMove(fromX, fromY, toX, toY, dt float64) (x, y float64, ok bool) {
+	ok = true
+
+	// Too far for the time taken - cut the move short
+	dt = math.Min(math.Max(dt, 0), MaxMoveInterval)
+	maxDistance := MaxPlayerSpeed*dt + MoveSlack
+	dx, dy := toX-fromX, toY-fromY
+	distance := math.Hypot(dx, dy)
+	if distance > maxDistance {
+		scale := maxDistance / distance
+		dx, dy = dx*scale, dy*scale
+		distance = maxDistance
+		ok = false
+	}
+
+	// A player somehow stuck in a wall may step straight out of it
+	if !cm.IsWalkableAt(fromX, fromY) {
+		if !cm.IsWalkableAt(fromX+dx, fromY+dy) {
+			return fromX, fromY, false
+		}
+		return fromX + dx, fromY + dy, ok
+	}
+
+	// Walk the path in small steps so thin walls can't be skipped over
+	x, y = fromX, fromY
+	steps := int(math.Ceil(distance / moveStep))
+	for i := 1; i <= steps; i++ {
+		t := float64(i) / float64(steps)
+		nextX, nextY := fromX+dx*t, fromY+dy*t
+		if !cm.IsWalkableAt(nextX, nextY) {
+			return x, y, false
+		}
+		x, y = nextX, nextY
+	}
+
+	if ok {
+		return toX, toY, true
+	}
+	return x, y, false
+}
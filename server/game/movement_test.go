@@ -0,0 +1,60 @@
+package game
+
+import (
+	"math"
+	"testing"
+)
+
+// movementTestMap is 20x20 tiles of floor with a wall down column 10
+// (rows 0-18) and the bottom row outside the map
+func movementTestMap() *CollisionMap {
+	const size = 20
+	mapData := &MapData{Width: size * TileSize, Height: size * TileSize}
+	for y := 0; y < size; y++ {
+		row := make([]int, size)
+		for x := range row {
+			row[x] = TileFloor
+			if y == size-1 {
+				row[x] = TileOutside
+			}
+		}
+		mapData.Terrain = append(mapData.Terrain, row)
+	}
+	for y := 0; y < size-1; y++ {
+		mapData.MapObjects = append(mapData.MapObjects, MapObject{ID: "7", X: 10, Y: y})
+	}
+	return NewCollisionMap(mapData)
+}
+
+func TestValidateMove(t *testing.T) {
+	cm := movementTestMap()
+	reach := func(dt float64) float64 { return MaxPlayerSpeed*dt + MoveSlack }
+
+	tests := []struct {
+		name         string
+		fromX, fromY float64
+		toX, toY     float64
+		dt           float64
+		wantX, wantY float64
+		wantOK       bool
+	}{
+		{"stand still", 40, 40, 40, 40, 0.1, 40, 40, true},
+		{"short move", 40, 40, 60, 40, 0.1, 60, 40, true},
+		{"too fast", 40, 40, 40, 140, 0.1, 40, 40 + reach(0.1), false},
+		{"long gaps are capped", 40, 40, 40, 240, 10, 40, 40 + reach(MaxMoveInterval), false},
+		{"negative time only allows slack", 40, 40, 40, 80, -1, 40, 40 + MoveSlack, false},
+		{"stops before a wall", 150, 40, 170, 40, 0.2, 158, 40, false},
+		{"stops before the outside", 40, 300, 40, 310, 0.2, 40, 300 + 10.0/3, false},
+		{"steps out of a wall", 168, 40, 180, 40, 0.1, 180, 40, true},
+		{"stuck in a wall", 168, 40, 166, 40, 0.1, 168, 40, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x, y, ok := cm.ValidateMove(tt.fromX, tt.fromY, tt.toX, tt.toY, tt.dt)
+			if ok != tt.wantOK || math.Abs(x-tt.wantX) > 1e-9 || math.Abs(y-tt.wantY) > 1e-9 {
+				t.Errorf("got (%v, %v, %v), want (%v, %v, %v)", x, y, ok, tt.wantX, tt.wantY, tt.wantOK)
+			}
+		})
+	}
+}
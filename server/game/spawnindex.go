@@ -0,0 +1,110 @@
+package game
+
+import (
+	"math"
+	"math/rand"
+)
+
+// SpawnIndex lists the tiles players may spawn on, found once per map so
+// choosing a spawn doesn't rescan the terrain and objects
+type SpawnIndex struct {
+	tiles []Point   // Every spawnable tile
+	zones [][]Point // Spawnable tiles in each of MapData.SpawnZones
+}
+
+// spawnSamples is how many random tiles Pick tries before scanning them all
+const spawnSamples = 32
+
+// NewSpawnIndex finds the spawnable tiles of a map: reachable floor that
+// no wall or cover object blocks
+func NewSpawnIndex(mapData *MapData, cm *CollisionMap) *SpawnIndex {
+	s := &SpawnIndex{}
+	for y, row := range mapData.Terrain {
+		for x, value := range row {
+			if value != TileOutside && !cm.IsSolid(x, y) {
+				s.tiles = append(s.tiles, Point{x, y})
+			}
+		}
+	}
+
+	s.zones = make([][]Point, len(mapData.SpawnZones))
+	for i, zone := range mapData.SpawnZones {
+		for y := zone.Y - zone.Radius; y <= zone.Y+zone.Radius; y++ {
+			for x := zone.X - zone.Radius; x <= zone.X+zone.Radius; x++ {
+				dx, dy := x-zone.X, y-zone.Y
+				if dx*dx+dy*dy > zone.Radius*zone.Radius || !inGrid(mapData.Terrain, x, y) {
+					continue
+				}
+				if mapData.Terrain[y][x] != TileOutside && !cm.IsSolid(x, y) {
+					s.zones[i] = append(s.zones[i], Point{x, y})
+				}
+			}
+		}
+	}
+	return s
+}
+
+// Len returns the number of spawnable tiles
+func (s *SpawnIndex) Len() int {
+	return len(s.tiles)
+}
+
+// Pick returns the pixel center of a random spawnable tile that accept
+// allows. A nil accept allows any tile.
+func (s *SpawnIndex) Pick(accept func(x, y float64) bool) (float64, float64, bool) {
+	return pickTile(s.tiles, accept)
+}
+
+// PickInZone is Pick limited to one spawn zone
+func (s *SpawnIndex) PickInZone(zone int, accept func(x, y float64) bool) (float64, float64, bool) {
+	return pickTile(s.zones[zone], accept)
+}
+
+// pickTile samples a few tiles at random, which finds one quickly unless
+// accept rules out most of them, then falls back to choosing uniformly
+// among every accepted tile
+func pickTile(tiles []Point, accept func(x, y float64) bool) (float64, float64, bool) {
+	if len(tiles) == 0 {
+		return 0, 0, false
+	}
+	for i := 0; i < spawnSamples; i++ {
+		tile := tiles[rand.Intn(len(tiles))]
+		x, y := TileCenter(tile.X, tile.Y)
+		if accept == nil || accept(x, y) {
+			return x, y, true
+		}
+	}
+
+	found := 0
+	var bestX, bestY float64
+	for _, tile := range tiles {
+		x, y := TileCenter(tile.X, tile.Y)
+		if !accept(x, y) {
+			continue
+		}
+		// Reservoir sampling keeps the choice uniform in one pass
+		found++
+		if rand.Intn(found) == 0 {
+			bestX, bestY = x, y
+		}
+	}
+	return bestX, bestY, found > 0
+}
+
+// TileCenter converts tile coordinates to the pixel at the tile's center
+func TileCenter(tx, ty int) (float64, float64) {
+	return float64(tx*TileSize) + TileSize/2, float64(ty*TileSize) + TileSize/2
+}
+
+// FarFrom returns an accept function for Pick allowing only positions at
+// least minDist pixels from every point
+func FarFrom(points [][2]float64, minDist float64) func(x, y float64) bool {
+	return func(x, y float64) bool {
+		for _, p := range points {
+			if math.Hypot(p[0]-x, p[1]-y) < minDist {
+				return false
+			}
+		}
+		return true
+	}
+}
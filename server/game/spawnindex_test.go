@@ -0,0 +1,128 @@
+package game
+
+import "testing"
+
+// spawnTestMap is 10x10 tiles with the top row and left column outside the
+// map and a wall at 5,5
+func spawnTestMap(zones ...SpawnZone) *MapData {
+	const size = 10
+	mapData := &MapData{Width: size * TileSize, Height: size * TileSize, SpawnZones: zones}
+	for y := 0; y < size; y++ {
+		row := make([]int, size)
+		for x := range row {
+			row[x] = TileFloor
+			if x == 0 || y == 0 {
+				row[x] = TileOutside
+			}
+		}
+		mapData.Terrain = append(mapData.Terrain, row)
+	}
+	mapData.MapObjects = []MapObject{{ID: "7", X: 5, Y: 5}}
+	return mapData
+}
+
+func TestNewSpawnIndex(t *testing.T) {
+	tests := []struct {
+		name string
+		zone SpawnZone
+		want int // Spawnable tiles in the zone
+	}{
+		{"around a wall", SpawnZone{X: 5, Y: 5, Radius: 1}, 4},
+		{"outside the map", SpawnZone{X: 0, Y: 0, Radius: 1}, 0},
+		{"clipped by the grid edge", SpawnZone{X: 9, Y: 9, Radius: 2}, 6},
+		{"radius zero", SpawnZone{X: 3, Y: 3, Radius: 0}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mapData := spawnTestMap(tt.zone)
+			cm := NewCollisionMap(mapData)
+			s := NewSpawnIndex(mapData, cm)
+
+			if s.Len() != 80 {
+				t.Errorf("%d spawnable tiles, want 80", s.Len())
+			}
+			if len(s.zones[0]) != tt.want {
+				t.Errorf("%d tiles in zone, want %d: %v", len(s.zones[0]), tt.want, s.zones[0])
+			}
+			for _, tile := range s.zones[0] {
+				if cm.IsSolid(tile.X, tile.Y) || mapData.Terrain[tile.Y][tile.X] == TileOutside {
+					t.Errorf("unspawnable tile %v in zone", tile)
+				}
+			}
+		})
+	}
+}
+
+func TestPickTile(t *testing.T) {
+	tiles := make([]Point, 0, 100)
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 10; x++ {
+			tiles = append(tiles, Point{x, y})
+		}
+	}
+	onlyX, onlyY := TileCenter(7, 3)
+
+	tests := []struct {
+		name   string
+		tiles  []Point
+		accept func(x, y float64) bool
+		wantOK bool
+		want   *Point // Tile that must be chosen, if only one can be
+	}{
+		{"no tiles", nil, nil, false, nil},
+		{"any tile", tiles, nil, true, nil},
+		{"none accepted", tiles, func(x, y float64) bool { return false }, false, nil},
+		// Random samples almost never find it, so the scan has to
+		{"one accepted", tiles, func(x, y float64) bool { return x == onlyX && y == onlyY }, true, &Point{7, 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 20; i++ {
+				x, y, ok := pickTile(tt.tiles, tt.accept)
+				if ok != tt.wantOK {
+					t.Fatalf("ok %v, want %v", ok, tt.wantOK)
+				}
+				if !ok {
+					continue
+				}
+				tx, ty := int(x)/TileSize, int(y)/TileSize
+				if tx < 0 || tx >= 10 || ty < 0 || ty >= 10 {
+					t.Fatalf("picked (%v, %v) off the tile list", x, y)
+				}
+				if tt.want != nil && (tx != tt.want.X || ty != tt.want.Y) {
+					t.Fatalf("picked tile %d,%d, want %v", tx, ty, *tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestFarFrom(t *testing.T) {
+	points := [][2]float64{{0, 0}, {100, 0}}
+
+	tests := []struct {
+		name string
+		x, y float64
+		want bool
+	}{
+		{"far from both", 50, 60, true},
+		{"on the boundary", 0, 60, true},
+		{"too close to one", 95, 0, false},
+		{"between them", 50, 0, false},
+	}
+
+	accept := FarFrom(points, 60)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := accept(tt.x, tt.y); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if !FarFrom(nil, 60)(0, 0) {
+		t.Error("no points should accept everywhere")
+	}
+}
@@ -1,9 +1,5 @@
 package game
 
-// MapSize increased to 200 for larger battle royale maps
-// This gives us 40,000 total tiles for 50-100 players
-const MapSize = 200
-
 type MapObject struct {
 	ID       string `json:"id"`
 	X        int    `json:"x"`
@@ -12,8 +8,27 @@ type MapObject struct {
 }
 
 type MapData struct {
-	Width      int                   `json:"width"`
-	Height     int                   `json:"height"`
-	MapObjects []MapObject           `json:"mapObjects"`
-	Terrain    [MapSize][MapSize]int `json:"terrain"`
+	Width      int         `json:"width"`
+	Height     int         `json:"height"`
+	MapObjects []MapObject `json:"mapObjects"`
+	Terrain    [][]int     `json:"terrain"` // Rows of tiles, Height/TileSize by Width/TileSize
+	Seed       int64       `json:"seed"`    // Generate with the room's config and Seed rebuilds this map
+	SpawnZones []SpawnZone `json:"spawnZones"`
+}
+
+// SpawnZone is an open area players spawn in, in tiles
+type SpawnZone struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Radius int `json:"radius"`
+}
+
+// Cols returns the map width in tiles
+func (m *MapData) Cols() int {
+	return m.Width / TileSize
+}
+
+// Rows returns the map height in tiles
+func (m *MapData) Rows() int {
+	return m.Height / TileSize
 }
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/AmeenAhmed/hackathon/wire"
+)
+
+// MinProtocolVersion is the oldest client protocol the server still speaks
+const MinProtocolVersion = 2
+
+// Optional features a client can ask for in its hello
+const (
+	FeatureDelta       = "delta"       // gameDelta updates against acked snapshots
+	FeatureCompression = "compression" // permessage-deflate on server messages
+	FeatureMsgPack     = "msgpack"     // MessagePack frames, chosen when connecting
+	FeatureCompactMap  = "compactMap"  // Maps sent as game.CompactMap instead of game.MapData
+)
+
+// features is what a connection agreed to in its hello. Fixed once the
+// handshake is done, which is before the client can join a room.
+type features struct {
+	delta       bool
+	compression bool
+	msgpack     bool
+	compactMap  bool
+}
+
+// list returns the enabled feature names
+func (f features) list() []string {
+	names := []string{}
+	if f.delta {
+		names = append(names, FeatureDelta)
+	}
+	if f.compression {
+		names = append(names, FeatureCompression)
+	}
+	if f.msgpack {
+		names = append(names, FeatureMsgPack)
+	}
+	if f.compactMap {
+		names = append(names, FeatureCompactMap)
+	}
+	return names
+}
+
+// offersDeflate reports whether a websocket upgrade request offers
+// permessage-deflate, which the upgrader then accepts
+func offersDeflate(r *http.Request) bool {
+	for _, value := range r.Header.Values("Sec-WebSocket-Extensions") {
+		for _, ext := range strings.Split(value, ",") {
+			name, _, _ := strings.Cut(ext, ";")
+			if strings.TrimSpace(name) == "permessage-deflate" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// handleHello checks the client's protocol version and turns on the
+// requested features this connection supports
+func (c *Client) handleHello(version int, requested []string) {
+	if c.helloDone {
+		c.sendError(CodeInvalidRequest, "Hello already received")
+		return
+	}
+	if version < MinProtocolVersion || version > ProtocolVersion {
+		log.Printf("Client %s speaks protocol %d, refusing", c.ID, version)
+		c.sendError(CodeUnsupportedVersion, fmt.Sprintf(
+			"Protocol version %d is not supported, the server speaks %d to %d. Reload to update the client.",
+			version, MinProtocolVersion, ProtocolVersion))
+		return
+	}
+
+	for _, name := range requested {
+		switch name {
+		case FeatureDelta:
+			c.features.delta = true
+		case FeatureCompression:
+			// Only possible if permessage-deflate was negotiated on upgrade
+			c.features.compression = c.compressionNegotiated
+			c.compress.Store(c.features.compression)
+		case FeatureMsgPack:
+			// The encoding is chosen by subprotocol or ?encoding= when
+			// connecting, so this only confirms it
+			c.features.msgpack = c.Format == wire.FormatMsgPack
+		case FeatureCompactMap:
+			c.features.compactMap = true
+		}
+	}
+	c.helloDone = true
+
+	response := struct {
+		Type            string   `json:"type"`
+		RequestID       string   `json:"requestId,omitempty"`
+		ProtocolVersion int      `json:"protocolVersion"`
+		Encoding        string   `json:"encoding"`
+		Features        []string `json:"features"` // Enabled for this connection
+	}{
+		Type:            "welcome",
+		RequestID:       c.request.RequestID,
+		ProtocolVersion: ProtocolVersion,
+		Encoding:        string(c.Format),
+		Features:        c.features.list(),
+	}
+	data, _ := json.Marshal(response)
+	c.Send <- wire.NewMessage(data)
+
+	log.Printf("Client %s said hello (protocol %d, features %v)", c.ID, version, response.Features)
+}
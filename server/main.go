@@ -4,19 +4,26 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"math"
 	"math/rand"
 	"net/http"
+	"os"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/AmeenAhmed/hackathon/game"
+	"github.com/AmeenAhmed/hackathon/quiz"
+	"github.com/AmeenAhmed/hackathon/wire"
 	"github.com/gorilla/websocket"
 )
 
 // Message types
 type Message struct {
-	Type    string          `json:"type"`
-	Content json.RawMessage `json:"content"`
+	Type      string          `json:"type"`
+	Content   json.RawMessage `json:"content"`
+	RequestID string          `json:"requestId,omitempty"` // Echoed on the reply and on any error
 }
 
 // Player represents a player in the game
@@ -33,11 +40,23 @@ type Player struct {
 	CurrentGun         int       `json:"currentGun"`
 	IsProtected        bool      `json:"isProtected"`
 	ProtectionExpiry   time.Time `json:"-"` // Don't send to client
+	QuizProtection     time.Time `json:"-"` // Protected while answering a question, until then
+	QuizCooldownEnds   time.Time `json:"-"` // No quiz protection again until then
+	QuizBudget         int       `json:"-"` // Questions left to ask, granted by deaths and empty guns
+	Ammo               game.Ammo `json:"-"` // Bullets left in each gun, counted by the server
+	DiedAt             time.Time `json:"-"` // When the player was last killed
+	MovedAt            time.Time `json:"-"` // When the last position update was accepted
+	Disconnected       bool      `json:"disconnected"`
+	DisconnectedAt     time.Time `json:"-"` // When the connection dropped, zero while connected
 	CorrectAnswers     int       `json:"correctAnswers"`
 	QuestionsAttempted int       `json:"questionsAttempted"`
 	Kills              int       `json:"kills"`
-	Health             float64   `json:"health"`
-	MaxHealth          float64   `json:"maxHealth"`
+	// Cumulative totals across all finished rounds in the room
+	TotalCorrectAnswers     int     `json:"totalCorrectAnswers"`
+	TotalQuestionsAttempted int     `json:"totalQuestionsAttempted"`
+	TotalKills              int     `json:"totalKills"`
+	Health                  float64 `json:"health"`
+	MaxHealth               float64 `json:"maxHealth"`
 }
 
 // Client represents a connected websocket client
@@ -47,32 +66,67 @@ type Client struct {
 	RoomCode    string
 	IsDashboard bool
 	Player      *Player
-	Send        chan []byte
+	Send        chan *wire.Message // JSON messages, encoded in Format by writePump
+	Format      wire.Format
+	limiter     *rateLimiter // Only used by readPump
+	request     Message      // Message being handled, readPump only
+	helloDone   bool         // Handshake accepted, readPump only
+	features    features     // Agreed in hello
+
+	compressionNegotiated bool        // permessage-deflate accepted on upgrade
+	compress              atomic.Bool // Compress outgoing messages, applied by writePump
+
+	ackedSnapshot atomic.Uint64               // Newest game state snapshot the client has applied
+	lastKeyframe  uint64                      // Snapshot of the last full state sent, ticker only
+	visible       [snapshotHistory]visibleSet // Players sent in recent snapshots, ticker only
 }
 
 // Room represents a game room
 type Room struct {
-	Code       string
-	Dashboard  *Client
-	Players    map[string]*Client
-	GameState  GameState
-	MapData    game.MapData
-	Created    time.Time
-	LastUpdate time.Time
-	TickRate   time.Duration
-	mutex      sync.RWMutex
-	broadcast  chan []byte
-	register   chan *Client
-	unregister chan *Client
-	stopTicker chan bool
+	Code          string
+	Dashboard     *Client
+	Players       map[string]*Client
+	GameState     GameState
+	MapData       game.MapData
+	mapCache      *encodedMap // MapData marshalled for joining clients
+	Created       time.Time
+	LastUpdate    time.Time
+	TickRate      time.Duration
+	Settings      RoomSettings
+	remaining     time.Duration // Match time left, owned by the ticker
+	lastTick      time.Time
+	countdownEnds time.Time
+	frozen        bool // Movement, shooting and timers suspended while paused
+	pausedAt      time.Time
+	Rounds        []RoundResult // Standings of every finished round
+	roundReset    bool          // Set by resetRound so setPhase can announce it
+	mutex         sync.RWMutex
+	register      chan *Client
+	unregister    chan *Client
+	done          chan struct{} // Closed when the room shuts down
+	closeOnce     sync.Once
+	emptySince    time.Time // When the last client left, zero while occupied
+	endedAt       time.Time // When the last round ended, zero while in play
+	Quiz          *quiz.Engine
+	collision     *game.CollisionMap
+	bullets       map[bulletKey]*game.Bullet
+	sessions      map[string]string          // Player ID -> reconnect token
+	dashToken     string                     // Reconnect token for the dashboard
+	snapshots     [snapshotHistory]*snapshot // Recent broadcasts, ticker only
+	snapshotSeq   uint64
+	grid          atomic.Pointer[spatialGrid] // Player positions as of the last tick
+	spawns        *game.SpawnIndex            // Where players may spawn on MapData
+	recentDeaths  []deathSite                 // Kills spawns keep away from
 }
 
 // GameState holds the current state of the game
 type GameState struct {
-	Players   map[string]*Player `json:"players"`
-	GamePhase string             `json:"gamePhase"` // "waiting", "playing", "ended"
-	Timer     int                `json:"timer"`
-	Score     map[string]int     `json:"score"`
+	Players    map[string]*Player `json:"players"`
+	GamePhase  GamePhase          `json:"gamePhase"`
+	Timer      int                `json:"timer"`
+	Score      map[string]int     `json:"score"`      // Current round
+	Round      int                `json:"round"`      // 1-based, 0 before the first round
+	TotalScore map[string]int     `json:"totalScore"` // Sum of finished rounds
 }
 
 // RoomManager manages all active rooms
@@ -86,6 +140,9 @@ var (
 	upgrader = websocket.Upgrader{
 		ReadBufferSize:  1024,
 		WriteBufferSize: 1024,
+		Subprotocols:    wire.Subprotocols,
+		// Offered to every client, but only used once asked for in hello
+		EnableCompression: true,
 		CheckOrigin: func(r *http.Request) bool {
 			return true
 		},
@@ -96,6 +153,12 @@ var (
 	}
 
 	playerColors = []string{"#FF6B6B", "#4ECDC4", "#45B7D1", "#FFA07A", "#98D8C8", "#6C5CE7", "#A8E6CF", "#FFD3B6"}
+
+	// Per-connection message limits, loaded once at startup
+	limitConfig = DefaultLimitConfig()
+
+	// Question banks, loaded once at startup
+	questionLibrary *quiz.Library
 )
 
 // Initialize random seed
@@ -114,7 +177,7 @@ func generateRoomCode() string {
 }
 
 // RoomManager methods
-func (rm *RoomManager) CreateRoom() *Room {
+func (rm *RoomManager) CreateRoom(bank *quiz.Bank, settings RoomSettings) *Room {
 	rm.mutex.Lock()
 	defer rm.mutex.Unlock()
 
@@ -127,8 +190,11 @@ func (rm *RoomManager) CreateRoom() *Room {
 		}
 	}
 
-	// Create new room with 30Hz tick rate
-	mapData := game.GenerateMap()
+	seed := game.NewSeed()
+	if settings.MapSeed != nil {
+		seed = *settings.MapSeed
+	}
+	mapData := game.Generate(settings.Map, seed)
 
 	// Debug: Count different types of objects
 	var wallCount, chestCount, cactusCount, lootCount int
@@ -144,25 +210,36 @@ func (rm *RoomManager) CreateRoom() *Room {
 			lootCount++
 		}
 	}
-	log.Printf("Generated map with %d total objects: %d walls, %d cacti, %d chests, %d loot items",
-		len(mapData.MapObjects), wallCount, cactusCount, chestCount, lootCount)
+	log.Printf("Generated map (seed %d) with %d total objects: %d walls, %d cacti, %d chests, %d loot items",
+		mapData.Seed, len(mapData.MapObjects), wallCount, cactusCount, chestCount, lootCount)
+	collision := game.NewCollisionMap(&mapData)
+	spawns := game.NewSpawnIndex(&mapData, collision)
+	log.Printf("Found %d spawnable tiles in %d spawn zones", spawns.Len(), len(mapData.SpawnZones))
 
 	room := &Room{
 		Code:       code,
 		Players:    make(map[string]*Client),
 		Created:    time.Now(),
-		TickRate:   time.Second / 60, // 60Hz for smoother updates
-		broadcast:  make(chan []byte, 256),
+		TickRate:   settings.TickInterval(),
+		Settings:   settings,
 		register:   make(chan *Client),
 		unregister: make(chan *Client),
-		stopTicker: make(chan bool),
+		done:       make(chan struct{}),
 		GameState: GameState{
-			Players:   make(map[string]*Player),
-			GamePhase: "waiting",
-			Timer:     300, // 5 minutes
-			Score:     make(map[string]int),
+			Players:    make(map[string]*Player),
+			GamePhase:  PhaseLobby,
+			Timer:      settings.MatchDuration,
+			Score:      make(map[string]int),
+			TotalScore: make(map[string]int),
 		},
-		MapData: mapData,
+		emptySince: time.Now(),
+		MapData:    mapData,
+		mapCache:   newEncodedMap(&mapData),
+		Quiz:       quiz.NewEngine(bank),
+		collision:  collision,
+		spawns:     spawns,
+		bullets:    make(map[bulletKey]*game.Bullet),
+		sessions:   make(map[string]string),
 	}
 
 	rm.rooms[code] = room
@@ -171,7 +248,8 @@ func (rm *RoomManager) CreateRoom() *Room {
 	go room.run()
 	go room.ticker()
 
-	log.Printf("Room created with code: %s", code)
+	log.Printf("Room created with code: %s (%dHz, %ds match, max %d players)",
+		code, settings.TickRate, settings.MatchDuration, settings.MaxPlayers)
 	return room
 }
 
@@ -182,14 +260,18 @@ func (rm *RoomManager) GetRoom(code string) (*Room, bool) {
 	return room, exists
 }
 
-func (rm *RoomManager) RemoveRoom(code string) {
+// RemoveRoom shuts a room down, telling anyone still in it why
+func (rm *RoomManager) RemoveRoom(code string, reason string) {
 	rm.mutex.Lock()
-	defer rm.mutex.Unlock()
-	if room, exists := rm.rooms[code]; exists {
-		room.stopTicker <- true
-		close(room.broadcast)
+	room, exists := rm.rooms[code]
+	if exists {
 		delete(rm.rooms, code)
-		log.Printf("Room %s removed", code)
+	}
+	rm.mutex.Unlock()
+
+	if exists {
+		room.shutdown(reason)
+		log.Printf("Room %s removed: %s", code, reason)
 	}
 }
 
@@ -203,12 +285,61 @@ func (r *Room) run() {
 		case client := <-r.unregister:
 			r.removeClient(client)
 
-		case message := <-r.broadcast:
-			r.broadcastToClients(message)
+		case <-r.done:
+			return
 		}
 	}
 }
 
+// registerClient hands a client to the run loop. It returns false if the
+// room has already shut down.
+func (r *Room) registerClient(client *Client) bool {
+	select {
+	case r.register <- client:
+		return true
+	case <-r.done:
+		return false
+	}
+}
+
+// unregisterClient hands a departing client to the run loop
+func (r *Room) unregisterClient(client *Client) {
+	select {
+	case r.unregister <- client:
+	case <-r.done:
+	}
+}
+
+// shutdown stops the room's goroutines and disconnects everyone still in it
+func (r *Room) shutdown(reason string) {
+	r.broadcastToAll(struct {
+		Type   string `json:"type"`
+		Reason string `json:"reason"`
+	}{
+		Type:   "roomClosed",
+		Reason: reason,
+	})
+
+	r.closeOnce.Do(func() { close(r.done) })
+
+	r.mutex.RLock()
+	clients := make([]*Client, 0, len(r.Players)+1)
+	for _, client := range r.Players {
+		clients = append(clients, client)
+	}
+	if r.Dashboard != nil {
+		clients = append(clients, r.Dashboard)
+	}
+	r.mutex.RUnlock()
+
+	// Give the write pumps a moment to flush roomClosed before hanging up
+	time.AfterFunc(time.Second, func() {
+		for _, client := range clients {
+			client.disconnect(websocket.CloseGoingAway, reason)
+		}
+	})
+}
+
 func (r *Room) ticker() {
 	ticker := time.NewTicker(r.TickRate)
 	defer ticker.Stop()
@@ -217,23 +348,313 @@ func (r *Room) ticker() {
 		select {
 		case <-ticker.C:
 			r.checkSpawnProtection()
+			r.purgeDisconnected()
+			r.updateBullets()
+			r.updateTimer()
 			r.broadcastGameState()
-		case <-r.stopTicker:
+		case <-r.done:
 			return
 		}
 	}
 }
 
-// checkSpawnProtection removes expired spawn protection
+// Quiz protection tuning
+const (
+	QuizProtectionDuration = 30 * time.Second // Longest a reload quiz protects a player
+	QuizCooldown           = 10 * time.Second // Between the end of one quiz protection and the next
+	QuizQuestions          = 3                // Asked after each death and to reload
+)
+
+// refreshProtection recomputes IsProtected from the server's own timers:
+// spawn protection after a respawn and a pending quiz question. Clients
+// never set it themselves.
+func (p *Player) refreshProtection(now time.Time) {
+	if !p.ProtectionExpiry.IsZero() && now.After(p.ProtectionExpiry) {
+		p.ProtectionExpiry = time.Time{}
+	}
+	if !p.QuizProtection.IsZero() && now.After(p.QuizProtection) {
+		p.endQuizProtection(now)
+	}
+	p.IsProtected = !p.ProtectionExpiry.IsZero() || !p.QuizProtection.IsZero()
+}
+
+// dropProtection ends all protection, as when the player opens fire
+func (p *Player) dropProtection() {
+	p.ProtectionExpiry = time.Time{}
+	p.endQuizProtection(time.Now())
+	p.IsProtected = false
+}
+
+// startQuizProtection protects a player answering reload questions. Only
+// players the server knows are out of ammo qualify, once per cooldown.
+// It reports whether the player is protected.
+func (p *Player) startQuizProtection(now time.Time) bool {
+	if !p.QuizProtection.IsZero() {
+		return true
+	}
+	if p.Health <= 0 || !p.Ammo.Empty() || now.Before(p.QuizCooldownEnds) {
+		return false
+	}
+	p.QuizProtection = now.Add(QuizProtectionDuration)
+	p.refreshProtection(now)
+	return true
+}
+
+// endQuizProtection lifts quiz protection and starts the cooldown
+func (p *Player) endQuizProtection(now time.Time) {
+	if p.QuizProtection.IsZero() {
+		return
+	}
+	p.QuizProtection = time.Time{}
+	p.QuizCooldownEnds = now.Add(QuizCooldown)
+	p.IsProtected = !p.ProtectionExpiry.IsZero()
+}
+
+// grantQuiz lets a player be asked QuizQuestions, after they die or run
+// out of ammo. Questions aren't handed out any other time, so a script
+// can't farm score from them.
+func (p *Player) grantQuiz() {
+	p.QuizBudget = QuizQuestions
+}
+
+// answeredQuestion spends one question of the budget. Once a player who
+// is out of ammo has used it up, every gun is refilled.
+func (p *Player) answeredQuestion(now time.Time) {
+	if p.QuizBudget > 0 {
+		p.QuizBudget--
+	}
+	if p.QuizBudget == 0 && p.Ammo.Empty() {
+		p.Ammo = game.FullAmmo()
+		p.endQuizProtection(now)
+	}
+}
+
+// resetAmmo gives a player the ammo they spawn with
+func (p *Player) resetAmmo() {
+	p.Ammo = game.StartingAmmo()
+}
+
+// checkSpawnProtection removes expired spawn and quiz protection
 func (r *Room) checkSpawnProtection() {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
+	// Protection doesn't run out while the game is paused
+	if r.frozen {
+		return
+	}
+
 	now := time.Now()
 	for _, player := range r.GameState.Players {
-		if player.IsProtected && !player.ProtectionExpiry.IsZero() && now.After(player.ProtectionExpiry) {
-			player.IsProtected = false
-			player.ProtectionExpiry = time.Time{} // Reset to zero value
+		player.refreshProtection(now)
+	}
+}
+
+// purgeDisconnected removes players who haven't rejoined within the rejoin window
+func (r *Room) purgeDisconnected() {
+	r.mutex.Lock()
+	window := r.Settings.RejoinWindowDuration()
+	var purged []string
+	for id, player := range r.GameState.Players {
+		if player.Disconnected && time.Since(player.DisconnectedAt) > window {
+			delete(r.GameState.Players, id)
+			delete(r.GameState.Score, id)
+			delete(r.GameState.TotalScore, id)
+			delete(r.sessions, id)
+			purged = append(purged, id)
+		}
+	}
+	r.mutex.Unlock()
+
+	for _, id := range purged {
+		r.Quiz.Forget(id)
+		log.Printf("Player %s removed from room %s after rejoin window expired", id, r.Code)
+
+		r.broadcastToAll(struct {
+			Type     string `json:"type"`
+			PlayerID string `json:"playerId"`
+		}{
+			Type:     "playerRemoved",
+			PlayerID: id,
+		})
+	}
+}
+
+// updateTimer counts the match clock down by the real time since the last
+// tick, broadcasting each whole second and ending the game at zero.
+// It also finishes the countdown phase once it has run its course.
+func (r *Room) updateTimer() {
+	r.mutex.Lock()
+	if r.GameState.GamePhase == PhaseCountdown && time.Now().After(r.countdownEnds) {
+		r.mutex.Unlock()
+		if err := r.setPhase(PhasePlaying); err != nil {
+			log.Printf("Room %s countdown finished: %v", r.Code, err)
+		}
+		return
+	}
+	if r.GameState.GamePhase != PhasePlaying {
+		r.mutex.Unlock()
+		return
+	}
+
+	now := time.Now()
+	r.remaining -= now.Sub(r.lastTick)
+	r.lastTick = now
+	if r.remaining < 0 {
+		r.remaining = 0
+	}
+
+	// Round up so the clock shows 1 until the very end
+	seconds := int((r.remaining + time.Second - 1) / time.Second)
+	changed := seconds != r.GameState.Timer
+	r.GameState.Timer = seconds
+	r.mutex.Unlock()
+
+	if changed {
+		r.broadcastToAll(struct {
+			Type  string `json:"type"`
+			Timer int    `json:"timer"`
+		}{
+			Type:  "timerUpdate",
+			Timer: seconds,
+		})
+	}
+
+	if seconds == 0 {
+		log.Printf("Match timer expired in room %s", r.Code)
+		if err := r.endGame(); err != nil {
+			log.Printf("Room %s could not end game: %v", r.Code, err)
+		}
+	}
+}
+
+// endGame moves the room to ended, which sends final scores to everyone
+func (r *Room) endGame() error {
+	return r.setPhase(PhaseEnded)
+}
+
+// bulletKey identifies a live bullet. Clients pick their own bullet IDs,
+// so an ID is only unique together with the player who fired it.
+type bulletKey struct {
+	owner string
+	id    string
+}
+
+// hitEvent is a bullet hit produced by the server-side simulation
+type hitEvent struct {
+	bulletID string
+	shooter  string
+	target   string
+	damage   float64
+	health   float64
+	isDead   bool
+	x, y     float64 // Where the target was hit
+}
+
+// updateBullets advances all live bullets one tick and applies any hits.
+// The server is the only authority on damage, health and kills.
+func (r *Room) updateBullets() {
+	r.mutex.Lock()
+
+	// Bullets hang in the air while the game is paused, and nothing
+	// lands outside a running match
+	if r.GameState.GamePhase != PhasePlaying {
+		r.mutex.Unlock()
+		return
+	}
+
+	dt := r.TickRate.Seconds()
+	var hits []hitEvent
+
+	for key, bullet := range r.bullets {
+		fromX, fromY, alive := bullet.Step(dt, r.collision)
+
+		// With friendly fire off bullets only collide with walls
+		if !r.Settings.FriendlyFire {
+			if !alive {
+				delete(r.bullets, key)
+			}
+			continue
+		}
+
+		// Sweep the travelled segment against every living, unprotected,
+		// connected player - disconnected ones are held for rejoin, not in play
+		for playerID, player := range r.GameState.Players {
+			if playerID == bullet.OwnerID || player.Health <= 0 || player.IsProtected || player.Disconnected {
+				continue
+			}
+			if !game.SegmentHitsCircle(fromX, fromY, bullet.X, bullet.Y, player.X, player.Y, game.PlayerHitRadius+game.BulletRadius) {
+				continue
+			}
+
+			damage := game.GunDamage(bullet.GunType)
+			player.Health -= damage
+			if player.Health < 0 {
+				player.Health = 0
+			}
+			hit := hitEvent{
+				bulletID: bullet.ID,
+				shooter:  bullet.OwnerID,
+				target:   playerID,
+				damage:   damage,
+				health:   player.Health,
+				isDead:   player.Health <= 0,
+				x:        player.X,
+				y:        player.Y,
+			}
+			if hit.isDead {
+				player.DiedAt = time.Now()
+				player.grantQuiz()
+				r.recordDeath(player.X, player.Y)
+				if shooter, exists := r.GameState.Players[bullet.OwnerID]; exists {
+					shooter.Kills++
+					r.GameState.Score[shooter.ID] = r.Settings.Score(shooter)
+				}
+			}
+			hits = append(hits, hit)
+			alive = false
+			break
+		}
+
+		if !alive {
+			delete(r.bullets, key)
+		}
+	}
+
+	r.mutex.Unlock()
+
+	// Broadcast outside the write lock - broadcasts take a read lock.
+	// Only those who can see the target hear about it, plus both parties.
+	for _, hit := range hits {
+		r.broadcastNear(hit.x, hit.y, struct {
+			Type           string  `json:"type"`
+			BulletID       string  `json:"bulletId"`
+			ShooterID      string  `json:"shooterId"`
+			TargetPlayerID string  `json:"targetPlayerId"`
+			Damage         int     `json:"damage"`
+			Health         float64 `json:"health"`
+			IsDead         bool    `json:"isDead"`
+		}{
+			Type:           "playerHit",
+			BulletID:       hit.bulletID,
+			ShooterID:      hit.shooter,
+			TargetPlayerID: hit.target,
+			Damage:         int(hit.damage),
+			Health:         hit.health,
+			IsDead:         hit.isDead,
+		}, hit.shooter, hit.target)
+
+		if hit.isDead {
+			r.broadcastNear(hit.x, hit.y, struct {
+				Type     string `json:"type"`
+				PlayerID string `json:"playerId"`
+				KillerID string `json:"killerId"`
+			}{
+				Type:     "playerDeath",
+				PlayerID: hit.target,
+				KillerID: hit.shooter,
+			}, hit.shooter, hit.target)
+			log.Printf("Player %s killed by %s in room %s", hit.target, hit.shooter, r.Code)
 		}
 	}
 }
@@ -248,6 +669,7 @@ func (r *Room) addClient(client *Client) {
 		r.Players[client.ID] = client
 		r.GameState.Players[client.ID] = client.Player
 	}
+	r.emptySince = time.Time{}
 }
 
 func (r *Room) removeClient(client *Client) {
@@ -255,50 +677,40 @@ func (r *Room) removeClient(client *Client) {
 	defer r.mutex.Unlock()
 
 	if client.IsDashboard {
-		r.Dashboard = nil
-		log.Printf("Dashboard disconnected from room %s", r.Code)
+		// A rejoin may already have replaced this connection
+		if r.Dashboard == client {
+			r.Dashboard = nil
+			log.Printf("Dashboard disconnected from room %s", r.Code)
+		}
 	} else {
-		// Remove from active players but keep in game state for rejoin
-		delete(r.Players, client.ID)
-		// Keep player data in GameState so they can rejoin with same name/color/position
-		// Only remove from GameState after a timeout or when room is destroyed
-		log.Printf("Player %s disconnected from room %s (data preserved for rejoin)", client.ID, r.Code)
-	}
-
-	close(client.Send)
-}
-
-func (r *Room) broadcastGameState() {
-	r.mutex.RLock()
-	defer r.mutex.RUnlock()
-
-	// Don't send MapData in regular updates - only GameState
-	// MapData is static and only needs to be sent once on join/rejoin
-	state := struct {
-		Type      string    `json:"type"`
-		GameState GameState `json:"gameState"`
-		Timestamp int64     `json:"timestamp"`
-	}{
-		Type:      "gameUpdate",
-		GameState: r.GameState,
-		Timestamp: time.Now().UnixMilli(),
+		// A rejoin may already have replaced this connection
+		if r.Players[client.ID] == client {
+			// Remove from active players but keep in game state for rejoin
+			delete(r.Players, client.ID)
+			// Keep player data in GameState so they can rejoin with same name/color/position
+			// until purgeDisconnected removes it after the rejoin window
+			if player, exists := r.GameState.Players[client.ID]; exists {
+				player.Disconnected = true
+				player.DisconnectedAt = time.Now()
+			}
+			log.Printf("Player %s disconnected from room %s (data preserved for rejoin)", client.ID, r.Code)
+		}
 	}
 
-	data, err := json.Marshal(state)
-	if err != nil {
-		log.Printf("Error marshaling game state: %v", err)
-		return
+	if len(r.Players) == 0 && r.Dashboard == nil {
+		r.emptySince = time.Now()
 	}
 
-	r.broadcast <- data
+	close(client.Send)
 }
 
 func (r *Room) broadcastToOthers(senderID string, message interface{}) {
-	data, err := json.Marshal(message)
+	encoded, err := json.Marshal(message)
 	if err != nil {
 		log.Printf("Error marshaling message: %v", err)
 		return
 	}
+	data := wire.NewMessage(encoded)
 
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
@@ -325,11 +737,12 @@ func (r *Room) broadcastToOthers(senderID string, message interface{}) {
 }
 
 func (r *Room) broadcastToAll(message interface{}) {
-	data, err := json.Marshal(message)
+	encoded, err := json.Marshal(message)
 	if err != nil {
 		log.Printf("Error marshaling message: %v", err)
 		return
 	}
+	data := wire.NewMessage(encoded)
 
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
@@ -357,18 +770,23 @@ func (r *Room) broadcastToAll(message interface{}) {
 
 func (r *Room) sendGameStateToClient(client *Client) {
 	r.mutex.RLock()
+	fullMap, compactMap := r.mapCache.forClient(client)
 	state := struct {
-		Type      string       `json:"type"`
-		RoomCode  string       `json:"roomCode"`
-		GameState GameState    `json:"gameState"`
-		MapData   game.MapData `json:"mapData"`
-		Timestamp int64        `json:"timestamp"`
+		Type       string          `json:"type"`
+		RoomCode   string          `json:"roomCode"`
+		GameState  GameState       `json:"gameState"`
+		MapData    json.RawMessage `json:"mapData,omitempty"`    // game.MapData
+		CompactMap json.RawMessage `json:"compactMap,omitempty"` // game.CompactMap, if enabled in hello
+		Settings   RoomSettings    `json:"settings"`
+		Timestamp  int64           `json:"timestamp"`
 	}{
-		Type:      "initialState",
-		RoomCode:  r.Code,
-		GameState: r.GameState,
-		MapData:   r.MapData,
-		Timestamp: time.Now().UnixMilli(),
+		Type:       "initialState",
+		RoomCode:   r.Code,
+		GameState:  r.visibleState(client),
+		MapData:    fullMap,
+		CompactMap: compactMap,
+		Settings:   r.Settings,
+		Timestamp:  time.Now().UnixMilli(),
 	}
 	r.mutex.RUnlock()
 
@@ -379,106 +797,63 @@ func (r *Room) sendGameStateToClient(client *Client) {
 	}
 
 	select {
-	case client.Send <- data:
+	case client.Send <- wire.NewMessage(data):
 	default:
 	}
 }
 
-func (r *Room) broadcastToClients(message []byte) {
-	r.mutex.RLock()
-	defer r.mutex.RUnlock()
+// updatePlayerPosition applies a client's reported state after checking the
+// move against the speed limit and the map. If the move was cut short it
+// returns the position the player was actually put at and corrected=true.
+func (r *Room) updatePlayerPosition(playerID string, x, y float64, animation string, direction string, gunRotation float64, gunFlipped bool, currentGun int) (float64, float64, bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
 
-	// Send to dashboard
-	if r.Dashboard != nil {
-		select {
-		case r.Dashboard.Send <- message:
-		default:
-			log.Printf("Dashboard send buffer full")
-		}
+	// Nobody moves while the game is paused
+	if r.frozen {
+		return 0, 0, false
 	}
 
-	// Send to all players
-	for _, client := range r.Players {
-		if client != nil {
-			select {
-			case client.Send <- message:
-			default:
-				log.Printf("Player %s send buffer full", client.ID)
-			}
+	corrected := false
+	if player, exists := r.GameState.Players[playerID]; exists {
+		now := time.Now()
+		elapsed := now.Sub(player.MovedAt).Seconds()
+		validX, validY, ok := r.collision.ValidateMove(player.X, player.Y, x, y, elapsed)
+		if !ok {
+			x, y = validX, validY
+			corrected = true
 		}
-	}
-}
 
-func (r *Room) updatePlayerPosition(playerID string, x, y float64, animation string, direction string, gunRotation float64, gunFlipped bool, currentGun int, isProtected bool) {
-	r.mutex.Lock()
-	defer r.mutex.Unlock()
+		// Walking away from a reload quiz gives up its protection
+		if x != player.X || y != player.Y {
+			player.endQuizProtection(now)
+		}
 
-	if player, exists := r.GameState.Players[playerID]; exists {
 		player.X = x
 		player.Y = y
+		player.MovedAt = now
 		player.Animation = animation
 		player.Direction = direction
 		player.GunRotation = gunRotation
 		player.GunFlipped = gunFlipped
 		player.CurrentGun = currentGun
-		// Only update protection if client is setting it to true (in quiz mode)
-		// Don't override server-side spawn protection
-		if isProtected {
-			player.IsProtected = true
-		} else if player.ProtectionExpiry.IsZero() {
-			// Only remove protection if there's no spawn protection timer active
-			player.IsProtected = false
-		}
-		r.LastUpdate = time.Now()
-	}
-}
-
-// getRandomSpawnPoint returns a random spawn point from floor tiles (terrain 0-6)
-// Avoids spawning on tiles with terrain value -1 (outside map) or walls (7+)
-func (r *Room) getRandomSpawnPoint() (float64, float64) {
-	// Collect all valid floor positions (terrain values 0-6)
-	type FloorTile struct {
-		X, Y int
-	}
-	var floorTiles []FloorTile
-
-	// Look through the terrain array for valid floor tiles
-	for y := 0; y < game.MapSize; y++ {
-		for x := 0; x < game.MapSize; x++ {
-			terrainValue := r.MapData.Terrain[y][x]
-			// Floor tiles have values 0-6, avoid -1 (outside map) and 7+ (walls/objects)
-			if terrainValue >= 0 && terrainValue <= 6 {
-				// Also check that there's no wall object at this position
-				hasWall := false
-				for _, obj := range r.MapData.MapObjects {
-					if obj.X == x && obj.Y == y && (obj.ID == "7" || obj.ID == "8") {
-						hasWall = true
-						break
-					}
-				}
-				if !hasWall {
-					floorTiles = append(floorTiles, FloorTile{X: x, Y: y})
-				}
-			}
-		}
+		r.LastUpdate = now
 	}
+	return x, y, corrected
+}
 
-	log.Printf("Found %d floor tiles for spawning", len(floorTiles))
-
-	// If we have floor tiles, pick a random one
-	if len(floorTiles) > 0 {
-		tile := floorTiles[rand.Intn(len(floorTiles))]
-		// Convert tile coordinates to pixel coordinates (center of tile)
-		x := float64(tile.X*16 + 8)
-		y := float64(tile.Y*16 + 8)
-		log.Printf("Spawning at floor tile: tile(%d, %d) -> pixel(%.0f, %.0f)", tile.X, tile.Y, x, y)
-		return x, y
-	}
+// isFull reports whether the room has reached its player limit
+func (r *Room) isFull() bool {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return len(r.Players) >= r.Settings.MaxPlayers
+}
 
-	// Fallback: spawn at map center with some randomness
-	log.Printf("No floor tiles found! Using fallback spawn at center")
-	return float64(r.MapData.Width/2 + rand.Intn(200) - 100),
-		float64(r.MapData.Height/2 + rand.Intn(200) - 100)
+// isPlaying reports whether the match is running (not paused or over)
+func (r *Room) isPlaying() bool {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.GameState.GamePhase == PhasePlaying
 }
 
 // CORS middleware
@@ -499,24 +874,39 @@ func enableCORS(next http.HandlerFunc) http.HandlerFunc {
 
 // WebSocket handler
 func handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	// An ?encoding= parameter wins over the negotiated subprotocol
+	encoding := r.URL.Query().Get("encoding")
+	format, err := wire.ParseFormat(encoding)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Println("Upgrade error:", err)
 		return
 	}
+	if encoding == "" {
+		format, _ = wire.ParseFormat(conn.Subprotocol())
+	}
 
 	// Create client
 	client := &Client{
-		ID:   fmt.Sprintf("%d", time.Now().UnixNano()),
-		Conn: conn,
-		Send: make(chan []byte, 256),
+		ID:      fmt.Sprintf("%d", time.Now().UnixNano()),
+		Conn:    conn,
+		Send:    make(chan *wire.Message, 256),
+		Format:  format,
+		limiter: newRateLimiter(limitConfig),
+
+		compressionNegotiated: offersDeflate(r),
 	}
 
 	// Handle client messages
 	go client.writePump()
 	go client.readPump()
 
-	log.Printf("New client connected: %s", client.ID)
+	log.Printf("New client connected: %s (%s)", client.ID, client.Format)
 }
 
 // Client methods
@@ -524,12 +914,16 @@ func (c *Client) readPump() {
 	defer func() {
 		if c.RoomCode != "" {
 			if room, exists := roomManager.GetRoom(c.RoomCode); exists {
-				room.unregister <- c
+				room.unregisterClient(c)
 			}
 		}
 		c.Conn.Close()
+		if dropped := c.limiter.totalDropped(); dropped > 0 {
+			log.Printf("Client %s had %d messages dropped by rate limits: %v", c.ID, dropped, c.limiter.dropped)
+		}
 	}()
 
+	c.Conn.SetReadLimit(limitConfig.MaxMessageBytes)
 	c.Conn.SetReadDeadline(time.Now().Add(60 * time.Second))
 	c.Conn.SetPongHandler(func(string) error {
 		c.Conn.SetReadDeadline(time.Now().Add(60 * time.Second))
@@ -537,15 +931,46 @@ func (c *Client) readPump() {
 	})
 
 	for {
-		_, messageBytes, err := c.Conn.ReadMessage()
+		messageType, messageBytes, err := c.Conn.ReadMessage()
+		if err == websocket.ErrReadLimit {
+			// The connection has already sent a "message too big" close frame
+			log.Printf("Client %s sent a message over %d bytes, disconnecting", c.ID, limitConfig.MaxMessageBytes)
+			break
+		}
 		if err != nil {
 			log.Printf("Read error for client %s: %v", c.ID, err)
 			break
 		}
 
 		var msg Message
-		if err := json.Unmarshal(messageBytes, &msg); err != nil {
+		messageBytes, err = wire.Decode(messageType, messageBytes)
+		if err == nil {
+			err = json.Unmarshal(messageBytes, &msg)
+		}
+		c.request = msg
+
+		// Garbage counts against the limits like anything else
+		allowed, exceeded := c.limiter.allow(msg.Type)
+		if exceeded {
+			log.Printf("Client %s exceeded rate limits (%d messages dropped), disconnecting; server totals %v",
+				c.ID, c.limiter.totalDropped(), droppedMessages.totals())
+			c.disconnect(websocket.ClosePolicyViolation, "Rate limit exceeded")
+			break
+		}
+		if !allowed {
+			// Only say so once per window rather than echo a flood
+			if c.limiter.windowDrops == 1 {
+				c.sendError(CodeRateLimited, "Too many "+msg.Type+" messages, some were dropped")
+			}
+			continue
+		}
+		if err != nil {
 			log.Printf("Error parsing message from client %s: %v", c.ID, err)
+			c.sendError(CodeBadMessage, "Invalid message")
+			continue
+		}
+		if !c.helloDone && msg.Type != "hello" {
+			c.sendError(CodeHelloRequired, fmt.Sprintf("Send hello with protocolVersion %d first", ProtocolVersion))
 			continue
 		}
 
@@ -569,7 +994,13 @@ func (c *Client) writePump() {
 				return
 			}
 
-			c.Conn.WriteMessage(websocket.TextMessage, message)
+			messageType, data, err := message.Encode(c.Format)
+			if err != nil {
+				log.Printf("Error encoding message for client %s: %v", c.ID, err)
+				continue
+			}
+			c.Conn.EnableWriteCompression(c.compress.Load())
+			c.Conn.WriteMessage(messageType, data)
 
 		case <-ticker.C:
 			c.Conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
@@ -582,8 +1013,33 @@ func (c *Client) writePump() {
 
 func (c *Client) handleMessage(msg Message) {
 	switch msg.Type {
+	case "hello":
+		var data struct {
+			ProtocolVersion int      `json:"protocolVersion"`
+			Features        []string `json:"features"` // Optional features wanted, see welcome
+		}
+		if err := json.Unmarshal(msg.Content, &data); err != nil {
+			c.badContent(err)
+			return
+		}
+		c.handleHello(data.ProtocolVersion, data.Features)
+
 	case "createRoom":
-		c.handleCreateRoom()
+		var data struct {
+			Bank       string          `json:"bank"`
+			BankImport *bankImport     `json:"bankImport"`
+			Settings   json.RawMessage `json:"settings"`
+		}
+		if len(msg.Content) > 0 {
+			if err := json.Unmarshal(msg.Content, &data); err != nil {
+				c.badContent(err)
+				return
+			}
+		}
+		c.handleCreateRoom(data.Bank, data.BankImport, data.Settings)
+
+	case "listBanks":
+		c.handleListBanks()
 
 	case "startGame":
 		c.handleStartGame()
@@ -594,7 +1050,7 @@ func (c *Client) handleMessage(msg Message) {
 			Name string `json:"name"`
 		}
 		if err := json.Unmarshal(msg.Content, &data); err != nil {
-			log.Printf("Error parsing joinRoom message: %v", err)
+			c.badContent(err)
 			return
 		}
 		// log.Printf("Received joinRoom - Code: %s, Name: %s", data.Code, data.Name)
@@ -609,13 +1065,12 @@ func (c *Client) handleMessage(msg Message) {
 			GunRotation float64 `json:"gunRotation"`
 			GunFlipped  bool    `json:"gunFlipped"`
 			CurrentGun  int     `json:"currentGun"`
-			IsProtected bool    `json:"isProtected"`
 		}
 		if err := json.Unmarshal(msg.Content, &data); err != nil {
-			log.Printf("Error parsing updatePosition message: %v", err)
+			c.badContent(err)
 			return
 		}
-		c.handleUpdatePosition(data.X, data.Y, data.Animation, data.Direction, data.GunRotation, data.GunFlipped, data.CurrentGun, data.IsProtected)
+		c.handleUpdatePosition(data.X, data.Y, data.Animation, data.Direction, data.GunRotation, data.GunFlipped, data.CurrentGun)
 
 	case "bulletSpawn":
 		var data struct {
@@ -628,7 +1083,7 @@ func (c *Client) handleMessage(msg Message) {
 			GunType   int     `json:"gunType"`
 		}
 		if err := json.Unmarshal(msg.Content, &data); err != nil {
-			log.Printf("Error parsing bulletSpawn message: %v", err)
+			c.badContent(err)
 			return
 		}
 		c.handleBulletSpawn(data.BulletID, data.X, data.Y, data.VelocityX, data.VelocityY, data.Angle, data.GunType)
@@ -638,7 +1093,7 @@ func (c *Client) handleMessage(msg Message) {
 			BulletID string `json:"bulletId"`
 		}
 		if err := json.Unmarshal(msg.Content, &data); err != nil {
-			log.Printf("Error parsing bulletDestroy message: %v", err)
+			c.badContent(err)
 			return
 		}
 		c.handleBulletDestroy(data.BulletID)
@@ -652,7 +1107,7 @@ func (c *Client) handleMessage(msg Message) {
 			IsDead         bool    `json:"isDead"`
 		}
 		if err := json.Unmarshal(msg.Content, &data); err != nil {
-			log.Printf("Error parsing playerHit message: %v", err)
+			c.badContent(err)
 			return
 		}
 		c.handlePlayerHit(data.BulletID, data.TargetPlayerID, data.Damage, data.Health, data.IsDead)
@@ -662,7 +1117,7 @@ func (c *Client) handleMessage(msg Message) {
 			PlayerID string `json:"playerId"`
 		}
 		if err := json.Unmarshal(msg.Content, &data); err != nil {
-			log.Printf("Error parsing playerDeath message: %v", err)
+			c.badContent(err)
 			return
 		}
 		c.handlePlayerDeath(data.PlayerID)
@@ -674,121 +1129,225 @@ func (c *Client) handleMessage(msg Message) {
 			Y        float64 `json:"y"`
 		}
 		if err := json.Unmarshal(msg.Content, &data); err != nil {
-			log.Printf("Error parsing playerRespawn message: %v", err)
+			c.badContent(err)
 			return
 		}
 		c.handlePlayerRespawn(data.PlayerID, data.X, data.Y)
 
 	case "updateGamePhase":
 		var data struct {
-			Phase string `json:"phase"`
+			Phase GamePhase `json:"phase"`
 		}
 		if err := json.Unmarshal(msg.Content, &data); err != nil {
-			log.Printf("Error parsing updateGamePhase message: %v", err)
+			c.badContent(err)
 			return
 		}
 		c.handleUpdateGamePhase(data.Phase)
 
 	case "rejoinRoom":
 		var data struct {
-			Code     string `json:"code"`
-			PlayerID string `json:"playerId"`
+			Code         string `json:"code"`
+			PlayerID     string `json:"playerId"`
+			SessionToken string `json:"sessionToken"`
 		}
 		if err := json.Unmarshal(msg.Content, &data); err != nil {
-			log.Printf("Error parsing rejoinRoom message: %v", err)
+			c.badContent(err)
 			return
 		}
-		c.handleRejoinRoom(data.Code, data.PlayerID)
+		c.handleRejoinRoom(data.Code, data.PlayerID, data.SessionToken)
 
 	case "rejoinDashboard":
 		var data struct {
-			Code string `json:"code"`
+			Code         string `json:"code"`
+			SessionToken string `json:"sessionToken"`
 		}
 		if err := json.Unmarshal(msg.Content, &data); err != nil {
-			log.Printf("Error parsing rejoinDashboard message: %v", err)
+			c.badContent(err)
 			return
 		}
-		c.handleRejoinDashboard(data.Code)
+		c.handleRejoinDashboard(data.Code, data.SessionToken)
+
+	case "snapshotAck":
+		var data struct {
+			Snapshot uint64 `json:"snapshot"`
+		}
+		if err := json.Unmarshal(msg.Content, &data); err != nil {
+			c.badContent(err)
+			return
+		}
+		// Without the delta feature every update stays a full keyframe
+		if c.features.delta {
+			c.ackSnapshot(data.Snapshot)
+		}
 
 	case "getState":
 		// Send current game state to the requesting client
 		c.handleGetState()
 
-	case "updateScore":
+	case "requestQuestion":
+		c.handleRequestQuestion()
+
+	case "submitAnswer":
 		var data struct {
-			CorrectAnswers     int `json:"correctAnswers"`
-			QuestionsAttempted int `json:"questionsAttempted"`
-			Kills              int `json:"kills"`
+			QuestionID string `json:"questionId"`
+			Answer     string `json:"answer"`
 		}
 		if err := json.Unmarshal(msg.Content, &data); err != nil {
-			log.Printf("Error parsing updateScore message: %v", err)
+			c.badContent(err)
 			return
 		}
-		c.handleUpdateScore(data.CorrectAnswers, data.QuestionsAttempted, data.Kills)
+		c.handleSubmitAnswer(data.QuestionID, data.Answer)
 
 	case "endGame":
 		c.handleEndGame()
 
-	case "updateTimer":
+	case "newRound":
 		var data struct {
-			Timer int `json:"timer"`
+			RegenerateMap bool `json:"regenerateMap"`
 		}
-		if err := json.Unmarshal(msg.Content, &data); err != nil {
-			log.Printf("Error parsing updateTimer message: %v", err)
-			return
+		if len(msg.Content) > 0 {
+			if err := json.Unmarshal(msg.Content, &data); err != nil {
+				c.badContent(err)
+				return
+			}
 		}
-		c.handleUpdateTimer(data.Timer)
+		c.handleNewRound(data.RegenerateMap)
+
+	case "pauseGame":
+		c.handlePauseGame()
+
+	case "resumeGame":
+		c.handleResumeGame()
+
+	default:
+		c.sendError(CodeUnknownType, fmt.Sprintf("Unknown message type %q", msg.Type))
 	}
 }
 
-func (c *Client) handleCreateRoom() {
+// bankImport is a question bank file uploaded by the dashboard
+type bankImport struct {
+	Name   string `json:"name"`
+	Format string `json:"format"` // "json", "csv", "gift" or "moodle"
+	Data   string `json:"data"`
+}
+
+// resolveBank picks the question bank for a new room: an uploaded bank
+// takes priority, then a named library bank, then the default bank
+func resolveBank(name string, upload *bankImport) (*quiz.Bank, error) {
+	if upload != nil {
+		bankName := upload.Name
+		if bankName == "" {
+			bankName = "upload"
+		}
+		return quiz.Parse(bankName, upload.Format, strings.NewReader(upload.Data))
+	}
+
+	if name == "" {
+		name = quiz.DefaultBankName
+	}
+	bank, exists := questionLibrary.Get(name)
+	if !exists {
+		return nil, fmt.Errorf("question bank %q not found", name)
+	}
+	return bank, nil
+}
+
+func (c *Client) handleCreateRoom(bankName string, upload *bankImport, rawSettings json.RawMessage) {
+	settings, err := parseSettings(rawSettings)
+	if err != nil {
+		log.Printf("Rejected createRoom from client %s: %v", c.ID, err)
+		c.sendError(CodeInvalidSettings, err.Error())
+		return
+	}
+
+	bank, err := resolveBank(bankName, upload)
+	if err != nil {
+		log.Printf("Rejected createRoom from client %s: %v", c.ID, err)
+		c.sendError(CodeInvalidBank, err.Error())
+		return
+	}
+
 	c.IsDashboard = true
-	room := roomManager.CreateRoom()
+	room := roomManager.CreateRoom(bank, settings)
+
+	// The token is the only way back into the dashboard after a disconnect
+	token, err := room.issueDashboardSession()
+	if err != nil {
+		log.Printf("Error creating dashboard session for room %s: %v", room.Code, err)
+		roomManager.RemoveRoom(room.Code, "Could not create session")
+		c.sendError(CodeInternal, "Could not create session")
+		return
+	}
+
 	c.RoomCode = room.Code
-	room.register <- c
+	if !room.registerClient(c) {
+		c.sendError(CodeRoomNotFound, "Room not found")
+		return
+	}
 
 	// Send room code to dashboard
 	response := struct {
-		Type     string `json:"type"`
-		RoomCode string `json:"roomCode"`
+		Type          string       `json:"type"`
+		RequestID     string       `json:"requestId,omitempty"`
+		RoomCode      string       `json:"roomCode"`
+		QuestionBank  string       `json:"questionBank"`
+		QuestionCount int          `json:"questionCount"`
+		Settings      RoomSettings `json:"settings"`
+		SessionToken  string       `json:"sessionToken"` // Present with rejoinDashboard to reconnect
 	}{
-		Type:     "roomCreated",
-		RoomCode: room.Code,
+		Type:          "roomCreated",
+		RequestID:     c.request.RequestID,
+		RoomCode:      room.Code,
+		QuestionBank:  bank.Name,
+		QuestionCount: len(bank.Questions),
+		Settings:      room.Settings,
+		SessionToken:  token,
 	}
 
 	data, _ := json.Marshal(response)
-	c.Send <- data
+	c.Send <- wire.NewMessage(data)
 }
 
-func (c *Client) handleStartGame() {
-	// Only dashboard can start the game
-	if !c.IsDashboard {
-		log.Printf("Non-dashboard client tried to start game")
-		return
+// handleListBanks sends the available question banks to the dashboard
+func (c *Client) handleListBanks() {
+	type bankInfo struct {
+		Name          string `json:"name"`
+		QuestionCount int    `json:"questionCount"`
 	}
 
-	room, exists := roomManager.GetRoom(c.RoomCode)
-	if !exists {
-		log.Printf("Room not found for start game: %s", c.RoomCode)
-		return
+	banks := []bankInfo{}
+	for _, name := range questionLibrary.Names() {
+		if bank, exists := questionLibrary.Get(name); exists {
+			banks = append(banks, bankInfo{Name: name, QuestionCount: len(bank.Questions)})
+		}
 	}
 
-	// Update game phase to playing
-	room.mutex.Lock()
-	room.GameState.GamePhase = "playing"
-	room.mutex.Unlock()
-
-	// Broadcast game started to all clients
 	response := struct {
-		Type      string `json:"type"`
-		GamePhase string `json:"gamePhase"`
+		Type      string     `json:"type"`
+		RequestID string     `json:"requestId,omitempty"`
+		Banks     []bankInfo `json:"banks"`
 	}{
-		Type:      "gameStarted",
-		GamePhase: "playing",
+		Type:      "questionBanks",
+		RequestID: c.request.RequestID,
+		Banks:     banks,
 	}
 
 	data, _ := json.Marshal(response)
-	room.broadcastToAll(data)
+	c.Send <- wire.NewMessage(data)
+}
+
+func (c *Client) handleStartGame() {
+	// Only dashboard can start the game
+	room, ok := c.requireDashboard()
+	if !ok {
+		return
+	}
+
+	// Count down, then the ticker starts the match clock
+	if err := room.setPhase(PhaseCountdown); err != nil {
+		c.sendError(CodeInvalidPhase, err.Error())
+		return
+	}
 
 	log.Printf("Game started in room %s", c.RoomCode)
 }
@@ -796,20 +1355,17 @@ func (c *Client) handleStartGame() {
 func (c *Client) handleJoinRoom(code string, playerName string) {
 	room, exists := roomManager.GetRoom(code)
 	if !exists {
-		response := struct {
-			Type  string `json:"type"`
-			Error string `json:"error"`
-		}{
-			Type:  "error",
-			Error: "Room not found",
-		}
-		data, _ := json.Marshal(response)
-		c.Send <- data
+		c.sendError(CodeRoomNotFound, "Room not found")
 		return
 	}
 
-	// Create player at a random chest spawn point
-	spawnX, spawnY := room.getRandomSpawnPoint()
+	if room.isFull() {
+		c.sendError(CodeRoomFull, "Room is full")
+		return
+	}
+
+	// Create player in a spawn zone away from other players
+	spawnX, spawnY := room.spawnPoint(c.ID)
 	c.Player = &Player{
 		ID:          c.ID,
 		Name:        playerName,
@@ -821,55 +1377,81 @@ func (c *Client) handleJoinRoom(code string, playerName string) {
 		IsProtected: false, // No spawn protection on initial join
 		Health:      100,
 		MaxHealth:   100,
+		Ammo:        game.StartingAmmo(),
 	}
 
-	log.Printf("Created player - ID: %s, Name: %s, Color: %s, Spawn: (%.0f, %.0f) [Chest spawn]",
+	log.Printf("Created player - ID: %s, Name: %s, Color: %s, Spawn: (%.0f, %.0f)",
 		c.Player.ID, c.Player.Name, c.Player.Color, c.Player.X, c.Player.Y)
 
+	// The token is the only way back into this player after a disconnect
+	token, err := room.issueSession(c.ID)
+	if err != nil {
+		log.Printf("Error creating session for player %s: %v", c.ID, err)
+		c.sendError(CodeInternal, "Could not create session")
+		return
+	}
+
 	c.RoomCode = code
-	room.register <- c
+	if !room.registerClient(c) {
+		c.sendError(CodeRoomNotFound, "Room not found")
+		return
+	}
 
 	// Send success response with terrain data
+	fullMap, compactMap := room.currentMap().forClient(c)
 	response := struct {
-		Type     string       `json:"type"`
-		PlayerID string       `json:"playerId"`
-		Player   *Player      `json:"player"`
-		MapData  game.MapData `json:"mapData"`
+		Type         string          `json:"type"`
+		RequestID    string          `json:"requestId,omitempty"`
+		PlayerID     string          `json:"playerId"`
+		SessionToken string          `json:"sessionToken"`
+		Player       *Player         `json:"player"`
+		MapData      json.RawMessage `json:"mapData,omitempty"`    // game.MapData
+		CompactMap   json.RawMessage `json:"compactMap,omitempty"` // game.CompactMap, if enabled in hello
 	}{
-		Type:     "joinedRoom",
-		PlayerID: c.ID,
-		Player:   c.Player,
-		MapData:  room.MapData,
+		Type:         "joinedRoom",
+		RequestID:    c.request.RequestID,
+		PlayerID:     c.ID,
+		SessionToken: token,
+		Player:       c.Player,
+		MapData:      fullMap,
+		CompactMap:   compactMap,
 	}
 
 	data, _ := json.Marshal(response)
-	c.Send <- data
+	c.Send <- wire.NewMessage(data)
 }
 
-func (c *Client) handleRejoinRoom(code string, playerID string) {
+func (c *Client) handleRejoinRoom(code string, playerID string, sessionToken string) {
 	room, exists := roomManager.GetRoom(code)
 	if !exists {
-		response := struct {
-			Type  string `json:"type"`
-			Error string `json:"error"`
-		}{
-			Type:  "error",
-			Error: "Room not found",
-		}
-		data, _ := json.Marshal(response)
-		c.Send <- data
+		c.sendError(CodeRoomNotFound, "Room not found")
 		return
 	}
 
 	// Check if player exists in the room's game state
 	room.mutex.RLock()
 	existingPlayer, playerExists := room.GameState.Players[playerID]
+	oldClient := room.Players[playerID]
 	room.mutex.RUnlock()
 
+	// Taking over an existing player requires the token issued when they joined
+	if playerExists && !room.checkSession(playerID, sessionToken) {
+		log.Printf("Rejected rejoin of player %s in room %s from client %s: invalid session token", playerID, code, c.ID)
+		c.sendError(CodeInvalidSession, "Invalid session token")
+		return
+	}
+
 	if playerExists {
-		// Reuse existing player data but give them a new spawn point
+		// The same player connecting again wins - drop the older connection
+		if oldClient != nil && oldClient != c {
+			log.Printf("Player %s reconnected to room %s, closing previous connection", playerID, code)
+			oldClient.disconnect(websocket.ClosePolicyViolation, "Connected from another session")
+		}
+
+		// Reuse existing player data but give them a new spawn point.
+		// Health is kept, so dead players still wait out the respawn delay.
 		c.ID = playerID
-		spawnX, spawnY := room.getRandomSpawnPoint()
+		spawnX, spawnY := room.spawnPoint(playerID)
 		c.Player = &Player{
 			ID:                 existingPlayer.ID,
 			Name:               existingPlayer.Name,
@@ -879,11 +1461,19 @@ func (c *Client) handleRejoinRoom(code string, playerID string) {
 			Animation:          "idle",
 			Direction:          existingPlayer.Direction,
 			IsProtected:        false, // No spawn protection on rejoin
-			Health:             100,
-			MaxHealth:          100,
+			Health:             existingPlayer.Health,
+			MaxHealth:          existingPlayer.MaxHealth,
+			DiedAt:             existingPlayer.DiedAt,
 			CorrectAnswers:     existingPlayer.CorrectAnswers,
 			QuestionsAttempted: existingPlayer.QuestionsAttempted,
 			Kills:              existingPlayer.Kills,
+			QuizCooldownEnds:   existingPlayer.QuizCooldownEnds,
+			QuizBudget:         existingPlayer.QuizBudget,
+			Ammo:               existingPlayer.Ammo, // Reconnecting doesn't reload
+
+			TotalCorrectAnswers:     existingPlayer.TotalCorrectAnswers,
+			TotalQuestionsAttempted: existingPlayer.TotalQuestionsAttempted,
+			TotalKills:              existingPlayer.TotalKills,
 		}
 		// Set default direction if empty
 		if c.Player.Direction == "" {
@@ -892,11 +1482,15 @@ func (c *Client) handleRejoinRoom(code string, playerID string) {
 		log.Printf("Player %s rejoining room %s with existing data - Name: %s, Color: %s, New spawn: (%.0f, %.0f)",
 			playerID, code, c.Player.Name, c.Player.Color, spawnX, spawnY)
 	} else {
-		// Player wasn't in the room before, create new player data at a random chest spawn point
-		c.ID = playerID
-		spawnX, spawnY := room.getRandomSpawnPoint()
+		// Player wasn't in the room before, create new player data in a spawn zone
+		if room.isFull() {
+			c.sendError(CodeRoomFull, "Room is full")
+			return
+		}
+		// Unknown IDs aren't trusted - join under this connection's own ID
+		spawnX, spawnY := room.spawnPoint(c.ID)
 		c.Player = &Player{
-			ID:        playerID,
+			ID:        c.ID,
 			Name:      "Player",
 			Color:     playerColors[rand.Intn(len(playerColors))],
 			X:         spawnX,
@@ -905,8 +1499,17 @@ func (c *Client) handleRejoinRoom(code string, playerID string) {
 			Direction: "right",
 			Health:    100,
 			MaxHealth: 100,
+			Ammo:      game.StartingAmmo(),
 		}
-		log.Printf("Player %s joining room %s as new player", playerID, code)
+		log.Printf("Player %s joining room %s as new player", c.ID, code)
+	}
+
+	// Hand out a fresh token on every rejoin so a leaked one stops working
+	token, err := room.issueSession(c.ID)
+	if err != nil {
+		log.Printf("Error creating session for player %s: %v", c.ID, err)
+		c.sendError(CodeInternal, "Could not create session")
+		return
 	}
 
 	c.RoomCode = code
@@ -919,43 +1522,70 @@ func (c *Client) handleRejoinRoom(code string, playerID string) {
 			p.Y = c.Player.Y
 			p.IsProtected = c.Player.IsProtected
 			p.ProtectionExpiry = c.Player.ProtectionExpiry
+			p.QuizProtection = time.Time{}
 		}
 		room.mutex.Unlock()
 	}
 
-	room.register <- c
+	if !room.registerClient(c) {
+		c.sendError(CodeRoomNotFound, "Room not found")
+		return
+	}
 
 	// Send success response with player and terrain data
+	fullMap, compactMap := room.currentMap().forClient(c)
 	response := struct {
-		Type     string       `json:"type"`
-		PlayerID string       `json:"playerId"`
-		Player   *Player      `json:"player"`
-		Rejoined bool         `json:"rejoined"`
-		MapData  game.MapData `json:"mapData"`
+		Type         string          `json:"type"`
+		RequestID    string          `json:"requestId,omitempty"`
+		PlayerID     string          `json:"playerId"`
+		SessionToken string          `json:"sessionToken"`
+		Player       *Player         `json:"player"`
+		Rejoined     bool            `json:"rejoined"`
+		MapData      json.RawMessage `json:"mapData,omitempty"`    // game.MapData
+		CompactMap   json.RawMessage `json:"compactMap,omitempty"` // game.CompactMap, if enabled in hello
 	}{
-		Type:     "rejoinedRoom",
-		PlayerID: c.ID,
-		Player:   c.Player,
-		Rejoined: playerExists,
-		MapData:  room.MapData,
+		Type:         "rejoinedRoom",
+		RequestID:    c.request.RequestID,
+		PlayerID:     c.ID,
+		SessionToken: token,
+		Player:       c.Player,
+		Rejoined:     playerExists,
+		MapData:      fullMap,
+		CompactMap:   compactMap,
 	}
 
 	data, _ := json.Marshal(response)
-	c.Send <- data
+	c.Send <- wire.NewMessage(data)
 }
 
-func (c *Client) handleRejoinDashboard(code string) {
+func (c *Client) handleRejoinDashboard(code string, sessionToken string) {
 	room, exists := roomManager.GetRoom(code)
 	if !exists {
-		response := struct {
-			Type  string `json:"type"`
-			Error string `json:"error"`
-		}{
-			Type:  "error",
-			Error: "Room not found",
-		}
-		data, _ := json.Marshal(response)
-		c.Send <- data
+		c.sendError(CodeRoomNotFound, "Room not found")
+		return
+	}
+
+	// Controlling the room requires the token issued when it was created
+	if !room.checkDashboardSession(sessionToken) {
+		log.Printf("Rejected dashboard rejoin of room %s from client %s: invalid session token", code, c.ID)
+		c.sendError(CodeInvalidSession, "Invalid session token")
+		return
+	}
+
+	// The dashboard connecting again wins - drop the older connection
+	room.mutex.RLock()
+	oldDashboard := room.Dashboard
+	room.mutex.RUnlock()
+	if oldDashboard != nil && oldDashboard != c {
+		log.Printf("Dashboard reconnected to room %s, closing previous connection", code)
+		oldDashboard.disconnect(websocket.ClosePolicyViolation, "Connected from another session")
+	}
+
+	// Hand out a fresh token on every rejoin so a leaked one stops working
+	token, err := room.issueDashboardSession()
+	if err != nil {
+		log.Printf("Error creating dashboard session for room %s: %v", code, err)
+		c.sendError(CodeInternal, "Could not create session")
 		return
 	}
 
@@ -964,60 +1594,134 @@ func (c *Client) handleRejoinDashboard(code string) {
 	c.RoomCode = code
 
 	// Register the dashboard with the room
-	room.register <- c
+	if !room.registerClient(c) {
+		c.sendError(CodeRoomNotFound, "Room not found")
+		return
+	}
 
 	// Send success response
+	fullMap, compactMap := room.currentMap().forClient(c)
 	response := struct {
-		Type      string       `json:"type"`
-		RoomCode  string       `json:"roomCode"`
-		MapData   game.MapData `json:"mapData"`
-		GameState GameState    `json:"gameState"`
+		Type         string          `json:"type"`
+		RequestID    string          `json:"requestId,omitempty"`
+		RoomCode     string          `json:"roomCode"`
+		MapData      json.RawMessage `json:"mapData,omitempty"`    // game.MapData
+		CompactMap   json.RawMessage `json:"compactMap,omitempty"` // game.CompactMap, if enabled in hello
+		GameState    GameState       `json:"gameState"`
+		SessionToken string          `json:"sessionToken"` // Replaces the token used to rejoin
 	}{
-		Type:      "rejoinedDashboard",
-		RoomCode:  code,
-		MapData:   room.MapData,
-		GameState: room.GameState,
+		Type:         "rejoinedDashboard",
+		RequestID:    c.request.RequestID,
+		RoomCode:     code,
+		MapData:      fullMap,
+		CompactMap:   compactMap,
+		SessionToken: token,
 	}
+
+	// The game loop mutates the state, so copy it out under the lock
+	room.mutex.RLock()
+	response.GameState = room.GameState
 	data, _ := json.Marshal(response)
-	c.Send <- data
+	room.mutex.RUnlock()
+	c.Send <- wire.NewMessage(data)
 
 	// Send initial game state with MapData
 	room.sendGameStateToClient(c)
 }
 
-func (c *Client) handleUpdatePosition(x, y float64, animation string, direction string, gunRotation float64, gunFlipped bool, currentGun int, isProtected bool) {
-	if c.RoomCode == "" || c.Player == nil {
+func (c *Client) handleUpdatePosition(x, y float64, animation string, direction string, gunRotation float64, gunFlipped bool, currentGun int) {
+	room, ok := c.requirePlayer()
+	if !ok {
 		return
 	}
 
-	room, exists := roomManager.GetRoom(c.RoomCode)
-	if !exists {
+	x, y, corrected := room.updatePlayerPosition(c.ID, x, y, animation, direction, gunRotation, gunFlipped, currentGun)
+	if !corrected {
 		return
 	}
 
-	room.updatePlayerPosition(c.ID, x, y, animation, direction, gunRotation, gunFlipped, currentGun, isProtected)
+	// Put the client back where the server has them
+	response := struct {
+		Type string  `json:"type"`
+		X    float64 `json:"x"`
+		Y    float64 `json:"y"`
+	}{
+		Type: "positionCorrection",
+		X:    x,
+		Y:    y,
+	}
+	data, _ := json.Marshal(response)
+	c.Send <- wire.NewMessage(data)
 }
 
 func (c *Client) handleBulletSpawn(bulletID string, x, y, velocityX, velocityY, angle float64, gunType int) {
-	if c.RoomCode == "" || c.Player == nil {
+	room, ok := c.requirePlayer()
+	if !ok {
 		return
 	}
 
-	room, exists := roomManager.GetRoom(c.RoomCode)
-	if !exists {
+	if !game.IsValidGun(gunType) {
+		log.Printf("Player %s fired unknown gun type %d", c.ID, gunType)
+		c.sendError(CodeInvalidRequest, fmt.Sprintf("Unknown gun type %d", gunType))
+		return
+	}
+
+	if bulletID == "" {
+		c.sendError(CodeInvalidRequest, "Missing bullet ID")
 		return
 	}
 
-	// Remove spawn protection when player shoots
 	room.mutex.Lock()
-	if player, exists := room.GameState.Players[c.ID]; exists && player.IsProtected {
-		player.IsProtected = false
-		player.ProtectionExpiry = time.Time{}
+	player, exists := room.GameState.Players[c.ID]
+	phase := room.GameState.GamePhase
+	if !exists || player.Health <= 0 || phase != PhasePlaying {
+		room.mutex.Unlock()
+
+		// Dead players can't shoot, and nobody shoots outside a running match
+		switch {
+		case !exists:
+			c.sendError(CodeNotInRoom, "Not in a room")
+		case phase == PhasePaused:
+			c.sendError(CodeInvalidPhase, "Game is paused")
+		case phase != PhasePlaying:
+			c.sendError(CodeInvalidPhase, "Game is not in progress")
+		default:
+			c.sendError(CodeForbidden, "Dead players can't shoot")
+		}
+		return
 	}
+
+	// Bullets must leave from the shooter's gun, not from across the map
+	if math.Hypot(x-player.X, y-player.Y) > game.MaxMuzzleOffset {
+		x, y = player.X, player.Y
+	}
+
+	// Track the bullet so the server can resolve hits itself
+	key := bulletKey{owner: c.ID, id: bulletID}
+	if _, exists := room.bullets[key]; exists {
+		room.mutex.Unlock()
+		c.sendError(CodeInvalidRequest, "Bullet ID already in use")
+		return
+	}
+	if player.Ammo[gunType] <= 0 {
+		room.mutex.Unlock()
+		c.sendError(CodeForbidden, "Out of ammo")
+		return
+	}
+	player.Ammo[gunType]--
+	if player.Ammo.Empty() {
+		player.grantQuiz()
+	}
+
+	// Shooting gives up spawn and quiz protection
+	player.dropProtection()
+	bullet := game.NewBullet(bulletID, c.ID, x, y, velocityX, velocityY, gunType)
+	room.bullets[key] = bullet
 	room.mutex.Unlock()
 
-	// Broadcast bullet spawn to all other clients
-	room.broadcastToOthers(c.ID, struct {
+	// Broadcast bullet spawn to the other clients who could see it fly
+	_, _, endX, endY := bullet.Path()
+	room.broadcastAlong(x, y, endX, endY, c.ID, struct {
 		Type      string  `json:"type"`
 		BulletID  string  `json:"bulletId"`
 		OwnerID   string  `json:"ownerId"`
@@ -1033,114 +1737,117 @@ func (c *Client) handleBulletSpawn(bulletID string, x, y, velocityX, velocityY,
 		OwnerID:   c.ID,
 		X:         x,
 		Y:         y,
-		VelocityX: velocityX,
-		VelocityY: velocityY,
+		VelocityX: bullet.VX,
+		VelocityY: bullet.VY,
 		Angle:     angle,
 		GunType:   gunType,
 	})
 }
 
 func (c *Client) handleBulletDestroy(bulletID string) {
-	if c.RoomCode == "" || c.Player == nil {
+	room, ok := c.requirePlayer()
+	if !ok {
 		return
 	}
 
-	room, exists := roomManager.GetRoom(c.RoomCode)
-	if !exists {
-		return
+	// Tell the clients who were told about the spawn - those who could see
+	// the bullet's path. One the server already retired is announced around
+	// the shooter instead.
+	// The server-side bullet is left alone: it expires on its own, and the
+	// shooter's view of the target may be ahead of the server's simulation
+	var x1, y1, x2, y2 float64
+	room.mutex.RLock()
+	if bullet, tracked := room.bullets[bulletKey{owner: c.ID, id: bulletID}]; tracked {
+		x1, y1, x2, y2 = bullet.Path()
+	} else if player, exists := room.GameState.Players[c.ID]; exists {
+		x1, y1, x2, y2 = player.X, player.Y, player.X, player.Y
 	}
+	room.mutex.RUnlock()
 
-	// Broadcast bullet destruction to all other clients
-	room.broadcastToOthers(c.ID, struct {
+	room.broadcastAlong(x1, y1, x2, y2, c.ID, struct {
 		Type     string `json:"type"`
 		BulletID string `json:"bulletId"`
+		OwnerID  string `json:"ownerId"` // Bullet IDs are only unique per owner
 	}{
 		Type:     "bulletDestroy",
 		BulletID: bulletID,
+		OwnerID:  c.ID,
 	})
 }
 
+// handlePlayerHit treats a client-reported hit as a hint only.
+// Damage is resolved by the server's bullet simulation in updateBullets.
 func (c *Client) handlePlayerHit(bulletID, targetPlayerID string, damage int, health float64, isDead bool) {
-	if c.RoomCode == "" || c.Player == nil {
+	room, ok := c.requirePlayer()
+	if !ok {
 		return
 	}
 
-	room, exists := roomManager.GetRoom(c.RoomCode)
-	if !exists {
-		return
-	}
+	room.mutex.RLock()
+	_, tracked := room.bullets[bulletKey{owner: c.ID, id: bulletID}]
+	room.mutex.RUnlock()
 
-	// Update player health on server
-	room.mutex.Lock()
-	if player, exists := room.GameState.Players[targetPlayerID]; exists {
-		player.Health = health
+	if !tracked {
+		log.Printf("Player %s reported hit on %s with unknown bullet %s (ignored)", c.ID, targetPlayerID, bulletID)
 	}
-	room.mutex.Unlock()
-
-	// Broadcast hit to all clients for visual effects
-	room.broadcastToAll(struct {
-		Type           string  `json:"type"`
-		BulletID       string  `json:"bulletId"`
-		TargetPlayerID string  `json:"targetPlayerId"`
-		Damage         int     `json:"damage"`
-		Health         float64 `json:"health"`
-		IsDead         bool    `json:"isDead"`
-	}{
-		Type:           "playerHit",
-		BulletID:       bulletID,
-		TargetPlayerID: targetPlayerID,
-		Damage:         damage,
-		Health:         health,
-		IsDead:         isDead,
-	})
 }
 
+// handlePlayerDeath ignores client-reported deaths.
+// playerDeath is emitted by the server when a simulated bullet kills a player.
 func (c *Client) handlePlayerDeath(playerID string) {
-	if c.RoomCode == "" || c.Player == nil {
+	if _, ok := c.requirePlayer(); !ok {
 		return
 	}
 
-	room, exists := roomManager.GetRoom(c.RoomCode)
-	if !exists {
-		return
-	}
-
-	// Broadcast death to all clients
-	room.broadcastToAll(struct {
-		Type     string `json:"type"`
-		PlayerID string `json:"playerId"`
-	}{
-		Type:     "playerDeath",
-		PlayerID: playerID,
-	})
+	log.Printf("Player %s reported death of %s (ignored, server-authoritative)", c.ID, playerID)
 }
 
 func (c *Client) handlePlayerRespawn(playerID string, x, y float64) {
-	if c.RoomCode == "" || c.Player == nil {
+	room, ok := c.requirePlayer()
+	if !ok {
 		return
 	}
 
-	room, exists := roomManager.GetRoom(c.RoomCode)
-	if !exists {
+	// Players can only respawn themselves, and only after the server killed them
+	if playerID != c.ID {
+		log.Printf("Player %s tried to respawn player %s", c.ID, playerID)
+		c.sendError(CodeForbidden, "Players can only respawn themselves")
+		return
+	}
+	room.mutex.RLock()
+	player, exists := room.GameState.Players[playerID]
+	isDead := exists && player.Health <= 0
+	waited := exists && time.Since(player.DiedAt) >= room.Settings.RespawnDelayDuration()
+	room.mutex.RUnlock()
+	if !isDead {
+		log.Printf("Player %s tried to respawn while alive", c.ID)
+		c.sendError(CodeForbidden, "Only dead players can respawn")
+		return
+	}
+	if !waited {
+		c.sendError(CodeTooEarly, "Respawn delay has not elapsed")
 		return
 	}
 
 	// Use server-determined spawn point (ignore client's x, y)
-	spawnX, spawnY := room.getRandomSpawnPoint()
+	spawnX, spawnY := room.spawnPoint(playerID)
 
-	// Update player position on server with 3 seconds spawn protection
+	// Update player position on server with the room's spawn protection
 	room.mutex.Lock()
 	if player, exists := room.GameState.Players[playerID]; exists {
 		player.X = spawnX
 		player.Y = spawnY
-		player.IsProtected = true                                 // Give spawn protection
-		player.ProtectionExpiry = time.Now().Add(3 * time.Second) // 3 seconds of protection
-		player.Health = player.MaxHealth                          // Reset health to full on respawn
+		player.Health = player.MaxHealth // Reset health to full on respawn
+		player.resetAmmo()
+		if protection := room.Settings.SpawnProtectionDuration(); protection > 0 {
+			player.IsProtected = true
+			player.ProtectionExpiry = time.Now().Add(protection)
+		}
 	}
 	room.mutex.Unlock()
 
-	// Broadcast respawn to all clients with server-determined position
-	room.broadcastToAll(struct {
+	// Broadcast respawn with server-determined position to those nearby
+	room.broadcastNear(spawnX, spawnY, struct {
 		Type     string  `json:"type"`
 		PlayerID string  `json:"playerId"`
 		X        float64 `json:"x"`
@@ -1150,35 +1857,27 @@ func (c *Client) handlePlayerRespawn(playerID string, x, y float64) {
 		PlayerID: playerID,
 		X:        spawnX,
 		Y:        spawnY,
-	})
+	}, playerID)
 }
 
-func (c *Client) handleUpdateGamePhase(phase string) {
-	if !c.IsDashboard || c.RoomCode == "" {
+func (c *Client) handleUpdateGamePhase(phase GamePhase) {
+	room, ok := c.requireDashboard()
+	if !ok {
 		return
 	}
 
-	room, exists := roomManager.GetRoom(c.RoomCode)
-	if !exists {
+	if !phase.IsValid() {
+		c.sendError(CodeInvalidRequest, fmt.Sprintf("Unknown game phase %q", phase))
 		return
 	}
-
-	room.mutex.Lock()
-	room.GameState.GamePhase = phase
-	room.mutex.Unlock()
-
-	log.Printf("Room %s game phase updated to: %s", c.RoomCode, phase)
+	if err := room.setPhase(phase); err != nil {
+		c.sendError(CodeInvalidPhase, err.Error())
+	}
 }
 
 func (c *Client) handleGetState() {
-	if c.RoomCode == "" {
-		log.Printf("Client %s requested state but not in a room", c.ID)
-		return
-	}
-
-	room, exists := roomManager.GetRoom(c.RoomCode)
-	if !exists {
-		log.Printf("Client %s requested state but room %s not found", c.ID, c.RoomCode)
+	room, ok := c.requireRoom()
+	if !ok {
 		return
 	}
 
@@ -1186,87 +1885,230 @@ func (c *Client) handleGetState() {
 	room.sendGameStateToClient(c)
 }
 
-func (c *Client) handleUpdateScore(correctAnswers int, questionsAttempted int, kills int) {
-	if c.RoomCode == "" || c.Player == nil {
+// disconnect closes the connection with a close frame explaining why
+func (c *Client) disconnect(code int, reason string) {
+	message := websocket.FormatCloseMessage(code, reason)
+	c.Conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(time.Second))
+	c.Conn.Close()
+}
+
+// handleRequestQuestion sends the player their next quiz question.
+// The correct answer stays on the server.
+func (c *Client) handleRequestQuestion() {
+	room, ok := c.requirePlayer()
+	if !ok {
 		return
 	}
 
-	room, exists := roomManager.GetRoom(c.RoomCode)
-	if !exists {
+	// Questions are part of the match
+	if !room.isPlaying() {
+		c.sendError(CodeInvalidPhase, "Game is not in progress")
 		return
 	}
 
-	room.mutex.Lock()
-	defer room.mutex.Unlock()
+	// Asking again before answering returns the same question, so only
+	// answers spend the budget
+	room.mutex.RLock()
+	player, exists := room.GameState.Players[c.ID]
+	budget := exists && player.QuizBudget > 0
+	room.mutex.RUnlock()
+	if !budget {
+		c.sendError(CodeForbidden, "Questions are only asked after dying or running out of ammo")
+		return
+	}
 
-	// Update player stats
-	if player, exists := room.GameState.Players[c.ID]; exists {
-		player.CorrectAnswers = correctAnswers
-		player.QuestionsAttempted = questionsAttempted
-		player.Kills = kills
+	question, err := room.Quiz.Next(c.ID)
+	if err != nil {
+		log.Printf("No question for player %s in room %s: %v", c.ID, c.RoomCode, err)
+		c.sendError(CodeNoQuestions, "No questions available")
+		return
+	}
 
-		// Calculate score: correctAnswers * 3 + kills
-		score := correctAnswers*3 + kills
-		room.GameState.Score[c.ID] = score
+	// Players the server knows are out of ammo can't be shot while they
+	// answer reload questions. Asking again doesn't extend it.
+	room.mutex.Lock()
+	if player, exists := room.GameState.Players[c.ID]; exists {
+		player.startQuizProtection(time.Now())
+	}
+	room.mutex.Unlock()
 
-		log.Printf("Player %s score updated: correctAnswers=%d, questionsAttempted=%d, kills=%d, score=%d",
-			c.ID, correctAnswers, questionsAttempted, kills, score)
+	response := struct {
+		Type      string              `json:"type"`
+		RequestID string              `json:"requestId,omitempty"`
+		Question  quiz.PublicQuestion `json:"question"`
+	}{
+		Type:      "question",
+		RequestID: c.request.RequestID,
+		Question:  question,
 	}
+
+	data, _ := json.Marshal(response)
+	c.Send <- wire.NewMessage(data)
 }
 
-func (c *Client) handleEndGame() {
-	// Only dashboard can end the game
-	if !c.IsDashboard {
-		log.Printf("Non-dashboard client tried to end game")
+// handleSubmitAnswer grades the player's answer and updates their score
+func (c *Client) handleSubmitAnswer(questionID, answer string) {
+	room, ok := c.requirePlayer()
+	if !ok {
 		return
 	}
 
-	room, exists := roomManager.GetRoom(c.RoomCode)
-	if !exists {
-		log.Printf("Room not found for end game: %s", c.RoomCode)
+	// Answers only score during a running match
+	if !room.isPlaying() {
+		c.sendError(CodeInvalidPhase, "Game is not in progress")
+		return
+	}
+
+	// A new round takes back any questions left over
+	room.mutex.RLock()
+	player, exists := room.GameState.Players[c.ID]
+	budget := exists && player.QuizBudget > 0
+	room.mutex.RUnlock()
+	if !budget {
+		c.sendError(CodeForbidden, "No question to answer")
+		return
+	}
+
+	result, err := room.Quiz.Submit(c.ID, questionID, answer)
+	if err != nil {
+		log.Printf("Rejected answer from player %s: %v", c.ID, err)
+		c.sendError(CodeInvalidRequest, "Invalid answer submission")
 		return
 	}
 
-	// Update game phase to ended
 	room.mutex.Lock()
-	room.GameState.GamePhase = "ended"
+	player, exists = room.GameState.Players[c.ID]
+	if !exists {
+		room.mutex.Unlock()
+		c.sendError(CodeNotInRoom, "Not in a room")
+		return
+	}
+	player.QuestionsAttempted++
+	if result.Correct {
+		player.CorrectAnswers++
+	}
+	player.answeredQuestion(time.Now())
+	score := room.Settings.Score(player)
+	room.GameState.Score[c.ID] = score
+	correctAnswers, questionsAttempted := player.CorrectAnswers, player.QuestionsAttempted
 	room.mutex.Unlock()
 
-	// Broadcast game ended to all clients
+	log.Printf("Player %s answered question %s: correct=%v, score=%d", c.ID, questionID, result.Correct, score)
+
 	response := struct {
-		Type      string         `json:"type"`
-		GamePhase string         `json:"gamePhase"`
-		Scores    map[string]int `json:"scores"`
+		Type      string `json:"type"`
+		RequestID string `json:"requestId,omitempty"`
+		quiz.Result
+		CorrectAnswers     int `json:"correctAnswers"`
+		QuestionsAttempted int `json:"questionsAttempted"`
+		Score              int `json:"score"`
 	}{
-		Type:      "gameEnded",
-		GamePhase: "ended",
-		Scores:    room.GameState.Score,
+		Type:               "answerResult",
+		RequestID:          c.request.RequestID,
+		Result:             result,
+		CorrectAnswers:     correctAnswers,
+		QuestionsAttempted: questionsAttempted,
+		Score:              score,
 	}
 
 	data, _ := json.Marshal(response)
-	room.broadcastToAll(data)
+	c.Send <- wire.NewMessage(data)
+}
 
-	log.Printf("Game ended in room %s", c.RoomCode)
+func (c *Client) handleEndGame() {
+	// Only dashboard can end the game
+	room, ok := c.requireDashboard()
+	if !ok {
+		return
+	}
+
+	// Dashboard ended the match early
+	if err := room.endGame(); err != nil {
+		c.sendError(CodeInvalidPhase, err.Error())
+	}
 }
 
-func (c *Client) handleUpdateTimer(timer int) {
-	// Only dashboard can update timer
-	if !c.IsDashboard {
+// handleNewRound starts another round in the same room, keeping
+// cumulative totals and optionally generating a fresh map
+func (c *Client) handleNewRound(regenerateMap bool) {
+	// Only dashboard can start a new round
+	room, ok := c.requireDashboard()
+	if !ok {
 		return
 	}
 
-	room, exists := roomManager.GetRoom(c.RoomCode)
-	if !exists {
+	room.mutex.RLock()
+	phase := room.GameState.GamePhase
+	room.mutex.RUnlock()
+	if phase != PhaseEnded && phase != PhaseResults {
+		c.sendError(CodeInvalidPhase, "A new round can only start after the current one has ended")
 		return
 	}
 
-	// Update timer in game state
-	room.mutex.Lock()
-	room.GameState.Timer = timer
-	room.mutex.Unlock()
+	if regenerateMap {
+		room.regenerateMap()
+	}
+
+	if err := room.setPhase(PhaseCountdown); err != nil {
+		c.sendError(CodeInvalidPhase, err.Error())
+		return
+	}
+
+	log.Printf("New round starting in room %s", c.RoomCode)
+}
+
+// handlePauseGame freezes the match so the teacher can talk to the class
+func (c *Client) handlePauseGame() {
+	// Only dashboard can pause the game
+	room, ok := c.requireDashboard()
+	if !ok {
+		return
+	}
+
+	if err := room.setPhase(PhasePaused); err != nil {
+		c.sendError(CodeInvalidPhase, err.Error())
+		return
+	}
+
+	log.Printf("Game paused in room %s", c.RoomCode)
+}
+
+// handleResumeGame continues a paused match where it left off
+func (c *Client) handleResumeGame() {
+	// Only dashboard can resume the game
+	room, ok := c.requireDashboard()
+	if !ok {
+		return
+	}
+
+	room.mutex.RLock()
+	paused := room.GameState.GamePhase == PhasePaused
+	room.mutex.RUnlock()
+	if !paused {
+		c.sendError(CodeInvalidPhase, "Game is not paused")
+		return
+	}
+
+	if err := room.setPhase(PhasePlaying); err != nil {
+		c.sendError(CodeInvalidPhase, err.Error())
+		return
+	}
+
+	log.Printf("Game resumed in room %s", c.RoomCode)
 }
 
 func main() {
+	// Load question banks (embedded default plus any in QUIZ_BANKS_DIR)
+	library, err := quiz.NewLibrary(os.Getenv("QUIZ_BANKS_DIR"))
+	if err != nil {
+		log.Fatalf("Failed to load question banks: %v", err)
+	}
+	questionLibrary = library
+	log.Printf("Loaded question banks: %v", questionLibrary.Names())
+
+	limitConfig = loadLimitConfig()
+	log.Printf("Max message size %d bytes, default rate limit %.0f/s", limitConfig.MaxMessageBytes, limitConfig.Default.Rate)
+
 	// WebSocket endpoint
 	http.HandleFunc("/ws", enableCORS(handleWebSocket))
 
@@ -1276,9 +2118,15 @@ func main() {
 		w.Write([]byte("OK"))
 	}))
 
+	// Garbage collect abandoned rooms
+	reaperConfig := loadReaperConfig()
+	go roomManager.reap(reaperConfig, nil)
+	log.Printf("Room reaper: idle %v, ended grace %v, max lifetime %v",
+		reaperConfig.IdleTimeout, reaperConfig.EndedGrace, reaperConfig.MaxLifetime)
+
 	log.Println("Game server starting on :8080")
 	log.Println("WebSocket endpoint: ws://localhost:8080/ws")
-	log.Println("60Hz tick rate enabled")
+	log.Printf("Default tick rate %dHz (configurable per room)", DefaultSettings().TickRate)
 
 	log.Fatal(http.ListenAndServe(":8080", nil))
 }
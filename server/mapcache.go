@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+
+	"github.com/AmeenAhmed/hackathon/game"
+	"github.com/AmeenAhmed/hackathon/wire"
+)
+
+// encodedMap is a room's map marshalled once, in both forms clients can
+// ask for, rather than on every join
+type encodedMap struct {
+	full    json.RawMessage // game.MapData
+	compact json.RawMessage // game.CompactMap, nil if the map couldn't be packed
+}
+
+// newEncodedMap marshals a freshly generated map
+func newEncodedMap(mapData *game.MapData) *encodedMap {
+	enc := &encodedMap{}
+
+	full, err := json.Marshal(mapData)
+	if err != nil {
+		log.Printf("Error marshaling map: %v", err)
+	}
+	enc.full = full
+
+	packed, err := game.Pack(mapData)
+	if err != nil {
+		// Clients fall back to the full map
+		log.Printf("Error packing map: %v", err)
+		return enc
+	}
+	enc.compact, _ = json.Marshal(packed)
+
+	log.Printf("Encoded map: %d bytes full, %d bytes compact", len(enc.full), len(enc.compact))
+	return enc
+}
+
+// currentMap returns the encoded form of the room's current map
+func (r *Room) currentMap() *encodedMap {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.mapCache
+}
+
+// forClient returns the map in the form the client asked for in hello.
+// Exactly one of the two is non-nil.
+func (e *encodedMap) forClient(c *Client) (full, compact json.RawMessage) {
+	if c.features.compactMap && e.compact != nil {
+		return nil, e.compact
+	}
+	return e.full, nil
+}
+
+// broadcastMapChanged sends a new map to everyone in the room, each in the
+// form they asked for
+func (r *Room) broadcastMapChanged(enc *encodedMap) {
+	messages := make(map[bool]*wire.Message, 2)
+	for _, compact := range []bool{false, true} {
+		message := struct {
+			Type       string          `json:"type"`
+			MapData    json.RawMessage `json:"mapData,omitempty"`    // game.MapData
+			CompactMap json.RawMessage `json:"compactMap,omitempty"` // game.CompactMap, if enabled in hello
+		}{Type: "mapChanged"}
+		if compact && enc.compact != nil {
+			message.CompactMap = enc.compact
+		} else {
+			message.MapData = enc.full
+		}
+		data, _ := json.Marshal(message)
+		messages[compact] = wire.NewMessage(data)
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	clients := make([]*Client, 0, len(r.Players)+1)
+	if r.Dashboard != nil {
+		clients = append(clients, r.Dashboard)
+	}
+	for _, client := range r.Players {
+		clients = append(clients, client)
+	}
+
+	for _, client := range clients {
+		select {
+		case client.Send <- messages[client.features.compactMap]:
+		default:
+			// Client's send channel is full, skip
+		}
+	}
+}
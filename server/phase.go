@@ -0,0 +1,295 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/AmeenAhmed/hackathon/game"
+	"github.com/AmeenAhmed/hackathon/wire"
+)
+
+// GamePhase is the lifecycle stage of a room's match
+type GamePhase string
+
+const (
+	PhaseLobby     GamePhase = "lobby"     // Players joining, nothing running
+	PhaseCountdown GamePhase = "countdown" // Short 3-2-1 before play starts
+	PhasePlaying   GamePhase = "playing"   // Match clock running
+	PhasePaused    GamePhase = "paused"    // Clock and movement frozen
+	PhaseEnded     GamePhase = "ended"     // Match over, final scores sent
+	PhaseResults   GamePhase = "results"   // Dashboard showing standings
+)
+
+// CountdownDuration is how long the countdown phase lasts before play starts
+const CountdownDuration = 3 * time.Second
+
+// phaseTransitions lists the phases each phase may move to
+var phaseTransitions = map[GamePhase][]GamePhase{
+	PhaseLobby:     {PhaseCountdown, PhasePlaying},
+	PhaseCountdown: {PhasePlaying, PhaseLobby},
+	PhasePlaying:   {PhasePaused, PhaseEnded},
+	PhasePaused:    {PhasePlaying, PhaseEnded},
+	PhaseEnded:     {PhaseResults, PhaseLobby, PhaseCountdown},
+	PhaseResults:   {PhaseLobby, PhaseCountdown},
+}
+
+// IsValid reports whether p is a known phase
+func (p GamePhase) IsValid() bool {
+	_, exists := phaseTransitions[p]
+	return exists
+}
+
+// CanTransitionTo reports whether moving from p to next is allowed
+func (p GamePhase) CanTransitionTo(next GamePhase) bool {
+	for _, allowed := range phaseTransitions[p] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// phaseHook runs with the room's write lock held.
+// other is the phase being left (on enter) or entered (on exit).
+type phaseHook func(r *Room, other GamePhase)
+
+// phaseHooks are the side effects of entering and leaving each phase
+var phaseHooks = map[GamePhase]struct {
+	enter phaseHook
+	exit  phaseHook
+}{
+	PhaseCountdown: {
+		enter: func(r *Room, from GamePhase) {
+			// Every round starts from a clean scoreboard, including the
+			// first one after the lobby
+			r.resetRound()
+			r.countdownEnds = time.Now().Add(CountdownDuration)
+			r.endedAt = time.Time{}
+		},
+	},
+	PhasePlaying: {
+		enter: func(r *Room, from GamePhase) {
+			r.lastTick = time.Now()
+			if from == PhasePaused {
+				return // Resume with the clock where it stopped
+			}
+			r.GameState.Round++
+			r.remaining = time.Duration(r.Settings.MatchDuration) * time.Second
+			r.GameState.Timer = r.Settings.MatchDuration
+		},
+	},
+	PhasePaused: {
+		enter: func(r *Room, from GamePhase) {
+			r.frozen = true
+			r.pausedAt = time.Now()
+		},
+		exit: func(r *Room, to GamePhase) {
+			r.frozen = false
+
+			// Push timers out by the pause length so time spent paused
+			// doesn't eat into spawn protection or respawn delays
+			paused := time.Since(r.pausedAt)
+			for _, player := range r.GameState.Players {
+				if !player.ProtectionExpiry.IsZero() {
+					player.ProtectionExpiry = player.ProtectionExpiry.Add(paused)
+				}
+				if !player.QuizProtection.IsZero() {
+					player.QuizProtection = player.QuizProtection.Add(paused)
+				}
+				if !player.DiedAt.IsZero() {
+					player.DiedAt = player.DiedAt.Add(paused)
+				}
+			}
+		},
+	},
+	PhaseEnded: {
+		enter: func(r *Room, from GamePhase) {
+			// Nothing in flight should land after the final whistle
+			r.bullets = make(map[bulletKey]*game.Bullet)
+			r.finishRound()
+			r.endedAt = time.Now()
+		},
+	},
+	PhaseLobby: {
+		enter: func(r *Room, from GamePhase) {
+			if from == PhaseEnded || from == PhaseResults {
+				r.resetRound()
+			}
+			r.endedAt = time.Time{}
+		},
+	},
+}
+
+// setPhase moves the room to next if the transition table allows it,
+// runs the exit/enter hooks and broadcasts the change
+func (r *Room) setPhase(next GamePhase) error {
+	r.mutex.Lock()
+	current := r.GameState.GamePhase
+	if !current.CanTransitionTo(next) {
+		r.mutex.Unlock()
+		return fmt.Errorf("cannot change phase from %s to %s", current, next)
+	}
+
+	if hook := phaseHooks[current].exit; hook != nil {
+		hook(r, next)
+	}
+	r.GameState.GamePhase = next
+	if hook := phaseHooks[next].enter; hook != nil {
+		hook(r, current)
+	}
+
+	timer := r.GameState.Timer
+	round := r.GameState.Round
+	scores := make(map[string]int, len(r.GameState.Score))
+	for id, score := range r.GameState.Score {
+		scores[id] = score
+	}
+	var summary roundSummary
+	if next == PhaseEnded {
+		summary = r.summary()
+	}
+
+	// Players were moved and healed for a new round - tell everyone where
+	var resetPlayers map[string]Player
+	if r.roundReset {
+		r.roundReset = false
+		resetPlayers = make(map[string]Player, len(r.GameState.Players))
+		for id, player := range r.GameState.Players {
+			resetPlayers[id] = *player
+		}
+	}
+	r.mutex.Unlock()
+
+	log.Printf("Room %s phase changed: %s -> %s", r.Code, current, next)
+
+	r.broadcastToAll(struct {
+		Type      string    `json:"type"`
+		From      GamePhase `json:"from"`
+		GamePhase GamePhase `json:"gamePhase"`
+		Timer     int       `json:"timer"`
+	}{
+		Type:      "phaseChanged",
+		From:      current,
+		GamePhase: next,
+		Timer:     timer,
+	})
+
+	// Keep the messages existing clients already listen for
+	switch {
+	case next == PhasePlaying && current != PhasePaused:
+		r.broadcastToAll(struct {
+			Type      string    `json:"type"`
+			GamePhase GamePhase `json:"gamePhase"`
+			Timer     int       `json:"timer"`
+		}{
+			Type:      "gameStarted",
+			GamePhase: next,
+			Timer:     timer,
+		})
+	case next == PhasePaused:
+		r.broadcastToAll(struct {
+			Type   string `json:"type"`
+			Paused bool   `json:"paused"`
+			Timer  int    `json:"timer"`
+		}{
+			Type:   "gamePaused",
+			Paused: true,
+			Timer:  timer,
+		})
+	case next == PhasePlaying:
+		// Resuming from pause
+		r.broadcastToAll(struct {
+			Type   string `json:"type"`
+			Paused bool   `json:"paused"`
+			Timer  int    `json:"timer"`
+		}{
+			Type:   "gameResumed",
+			Paused: false,
+			Timer:  timer,
+		})
+	case next == PhaseEnded:
+		r.broadcastToAll(struct {
+			Type      string         `json:"type"`
+			GamePhase GamePhase      `json:"gamePhase"`
+			Scores    map[string]int `json:"scores"`
+			roundSummary
+		}{
+			Type:         "gameEnded",
+			GamePhase:    next,
+			Scores:       scores,
+			roundSummary: summary,
+		})
+	}
+
+	if resetPlayers != nil {
+		r.broadcastRoundReset(round+1, resetPlayers)
+	}
+
+	return nil
+}
+
+// broadcastRoundReset tells every client where the players it may see
+// were respawned for a new round
+func (r *Room) broadcastRoundReset(round int, players map[string]Player) {
+	snap := &snapshot{Players: players}
+	grid := newSpatialGrid(players)
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	send := func(client *Client) {
+		view := players
+		if ids := r.viewFor(client, snap, grid); ids != nil {
+			view = snap.filtered(ids).Players
+		}
+		encoded, err := json.Marshal(struct {
+			Type    string            `json:"type"`
+			Round   int               `json:"round"`
+			Players map[string]Player `json:"players"`
+		}{
+			Type:    "roundReset",
+			Round:   round,
+			Players: view,
+		})
+		if err != nil {
+			log.Printf("Error marshaling round reset: %v", err)
+			return
+		}
+		select {
+		case client.Send <- wire.NewMessage(encoded):
+		default:
+			// Client's send channel is full, skip
+		}
+	}
+
+	if r.Dashboard != nil {
+		send(r.Dashboard)
+	}
+	for _, client := range r.Players {
+		send(client)
+	}
+}
+
+// resetRound clears the round's scores and restores every player at a
+// fresh spawn point. Cumulative totals are kept.
+// Called from phase hooks with the write lock held.
+func (r *Room) resetRound() {
+	r.GameState.Score = make(map[string]int)
+	r.bullets = make(map[bulletKey]*game.Bullet)
+	r.recentDeaths = nil
+	for _, player := range r.GameState.Players {
+		player.Kills = 0
+		player.CorrectAnswers = 0
+		player.QuestionsAttempted = 0
+		player.Health = player.MaxHealth
+		player.DiedAt = time.Time{}
+		player.X, player.Y = r.chooseSpawn(player.ID)
+		player.dropProtection()
+		player.resetAmmo()
+		player.QuizBudget = 0
+	}
+	r.GameState.Timer = r.Settings.MatchDuration
+	r.roundReset = true
+}
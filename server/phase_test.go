@@ -0,0 +1,133 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AmeenAhmed/hackathon/game"
+)
+
+// newTestRoom builds a room on a small seeded map without starting its
+// goroutines, so tests drive it directly
+func newTestRoom(t *testing.T) *Room {
+	t.Helper()
+	settings := DefaultSettings()
+	settings.Map = game.SmallMap
+	mapData := game.Generate(settings.Map, 1)
+	collision := game.NewCollisionMap(&mapData)
+	return &Room{
+		Code:     "TEST",
+		Players:  make(map[string]*Client),
+		Created:  time.Now(),
+		TickRate: settings.TickInterval(),
+		Settings: settings,
+		GameState: GameState{
+			Players:    make(map[string]*Player),
+			GamePhase:  PhaseLobby,
+			Timer:      settings.MatchDuration,
+			Score:      make(map[string]int),
+			TotalScore: make(map[string]int),
+		},
+		MapData:   mapData,
+		collision: collision,
+		spawns:    game.NewSpawnIndex(&mapData, collision),
+		bullets:   make(map[bulletKey]*game.Bullet),
+		sessions:  make(map[string]string),
+	}
+}
+
+func TestPhaseTransitions(t *testing.T) {
+	phases := []GamePhase{PhaseLobby, PhaseCountdown, PhasePlaying, PhasePaused, PhaseEnded, PhaseResults}
+	allowed := map[[2]GamePhase]bool{
+		{PhaseLobby, PhaseCountdown}:   true,
+		{PhaseLobby, PhasePlaying}:     true,
+		{PhaseCountdown, PhasePlaying}: true,
+		{PhaseCountdown, PhaseLobby}:   true,
+		{PhasePlaying, PhasePaused}:    true,
+		{PhasePlaying, PhaseEnded}:     true,
+		{PhasePaused, PhasePlaying}:    true,
+		{PhasePaused, PhaseEnded}:      true,
+		{PhaseEnded, PhaseResults}:     true,
+		{PhaseEnded, PhaseLobby}:       true,
+		{PhaseEnded, PhaseCountdown}:   true,
+		{PhaseResults, PhaseLobby}:     true,
+		{PhaseResults, PhaseCountdown}: true,
+	}
+
+	for _, from := range phases {
+		if !from.IsValid() {
+			t.Errorf("%s should be a valid phase", from)
+		}
+		for _, to := range phases {
+			want := allowed[[2]GamePhase{from, to}]
+			if got := from.CanTransitionTo(to); got != want {
+				t.Errorf("%s -> %s: got %v, want %v", from, to, got, want)
+			}
+		}
+	}
+
+	if GamePhase("halftime").IsValid() {
+		t.Error("unknown phase reported as valid")
+	}
+}
+
+func TestSetPhase(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    []GamePhase // Phases entered in order after the lobby
+		wantErr bool
+		want    GamePhase
+		frozen  bool
+	}{
+		{"start", []GamePhase{PhaseCountdown, PhasePlaying}, false, PhasePlaying, false},
+		{"pause", []GamePhase{PhaseCountdown, PhasePlaying, PhasePaused}, false, PhasePaused, true},
+		{"resume", []GamePhase{PhaseCountdown, PhasePlaying, PhasePaused, PhasePlaying}, false, PhasePlaying, false},
+		{"end while paused", []GamePhase{PhaseCountdown, PhasePlaying, PhasePaused, PhaseEnded}, false, PhaseEnded, false},
+		{"new round", []GamePhase{PhaseCountdown, PhasePlaying, PhaseEnded, PhaseCountdown}, false, PhaseCountdown, false},
+		{"pause from lobby", []GamePhase{PhasePaused}, true, PhaseLobby, false},
+		{"end from countdown", []GamePhase{PhaseCountdown, PhaseEnded}, true, PhaseCountdown, false},
+		{"unknown phase", []GamePhase{"halftime"}, true, PhaseLobby, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRoom(t)
+			var err error
+			for _, phase := range tt.path {
+				if err = r.setPhase(phase); err != nil {
+					break
+				}
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if r.GameState.GamePhase != tt.want {
+				t.Errorf("phase %s, want %s", r.GameState.GamePhase, tt.want)
+			}
+			if r.frozen != tt.frozen {
+				t.Errorf("frozen %v, want %v", r.frozen, tt.frozen)
+			}
+		})
+	}
+}
+
+func TestCountdownFromLobbyResetsStats(t *testing.T) {
+	r := newTestRoom(t)
+	r.GameState.Players["p1"] = &Player{ID: "p1", Health: 10, MaxHealth: 100, Kills: 4, CorrectAnswers: 2, QuestionsAttempted: 3}
+	r.GameState.Score["p1"] = 10
+
+	if err := r.setPhase(PhaseCountdown); err != nil {
+		t.Fatal(err)
+	}
+
+	player := r.GameState.Players["p1"]
+	if player.Kills != 0 || player.CorrectAnswers != 0 || player.QuestionsAttempted != 0 {
+		t.Errorf("stats not reset: %+v", player)
+	}
+	if player.Health != player.MaxHealth {
+		t.Errorf("health %v, want %v", player.Health, player.MaxHealth)
+	}
+	if len(r.GameState.Score) != 0 {
+		t.Errorf("scores not cleared: %v", r.GameState.Score)
+	}
+}
@@ -0,0 +1,8 @@
+package main
+
+//go:generate go run ./cmd/protodoc -o ../PROTOCOL.md
+
+// ProtocolVersion is bumped whenever a message changes incompatibly.
+// PROTOCOL.md documents this version and is generated from the message
+// types in this package by cmd/protodoc.
+const ProtocolVersion = 2
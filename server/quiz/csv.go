@@ -0,0 +1,87 @@
+package quiz
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ParseCSV parses a spreadsheet export with one question per row:
+//
+//	question,option 1,option 2,...,correct
+//
+// The last non-empty column is the correct option, given as its text,
+// its number (1, 2, ...) or its letter (A, B, ...). Text wins, so an
+// answer of "3" means the option "3" if there is one. A header row whose
+// first cell is "question" is skipped.
+func ParseCSV(name string, r io.Reader) (*Bank, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1 // Questions may have different option counts
+	reader.TrimLeadingSpace = true
+
+	var drafts []draftQuestion
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", name, err)
+		}
+
+		// Drop trailing empty cells left by spreadsheet exports
+		for len(record) > 0 && strings.TrimSpace(record[len(record)-1]) == "" {
+			record = record[:len(record)-1]
+		}
+		if len(record) == 0 {
+			continue
+		}
+		if row == 1 && strings.EqualFold(strings.TrimSpace(record[0]), "question") {
+			continue
+		}
+
+		draft := draftQuestion{
+			Source: fmt.Sprintf("row %d", row),
+			ID:     strconv.Itoa(row),
+			Text:   record[0],
+		}
+		if len(record) < 2 {
+			drafts = append(drafts, draft)
+			continue
+		}
+
+		options := record[1 : len(record)-1]
+		correct := csvAnswerIndex(strings.TrimSpace(record[len(record)-1]), options)
+		for i, option := range options {
+			draft.Choices = append(draft.Choices, Choice{
+				Text:    option,
+				Correct: i == correct,
+			})
+		}
+		drafts = append(drafts, draft)
+	}
+
+	return buildBank(name, drafts)
+}
+
+// csvAnswerIndex resolves the answer column to an option index, or -1.
+// Options are matched by text before the answer is read as a position.
+func csvAnswerIndex(answer string, options []string) int {
+	for i, option := range options {
+		if strings.EqualFold(strings.TrimSpace(option), answer) {
+			return i
+		}
+	}
+	if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(options) {
+		return n - 1
+	}
+	if len(answer) == 1 {
+		letter := strings.ToUpper(answer)[0]
+		if letter >= 'A' && int(letter-'A') < len(options) {
+			return int(letter - 'A')
+		}
+	}
+	return -1
+}
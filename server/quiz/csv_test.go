@@ -0,0 +1,70 @@
+package quiz
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []Question
+		wantErr bool
+	}{
+		{
+			name:  "answer by number",
+			input: "What is 2+2?,3,4,5,2\n",
+			want:  []Question{{ID: "1", Text: "What is 2+2?", Options: []string{"3", "4", "5"}, Answer: 1}},
+		},
+		{
+			name:  "answer by letter",
+			input: "Capital of France?,Berlin,Paris,Rome,b\n",
+			want:  []Question{{ID: "1", Text: "Capital of France?", Options: []string{"Berlin", "Paris", "Rome"}, Answer: 1}},
+		},
+		{
+			name:  "answer by text",
+			input: "Capital of Italy?,Berlin,Paris,Rome,rome\n",
+			want:  []Question{{ID: "1", Text: "Capital of Italy?", Options: []string{"Berlin", "Paris", "Rome"}, Answer: 2}},
+		},
+		{
+			name:  "numeric options match by text before position",
+			input: "What is 1+2?,2,3,4,3\n",
+			want:  []Question{{ID: "1", Text: "What is 1+2?", Options: []string{"2", "3", "4"}, Answer: 1}},
+		},
+		{
+			name:  "header and trailing cells skipped",
+			input: "question,a,b,answer\nSky colour?,Blue,Green,1,,\n",
+			want:  []Question{{ID: "2", Text: "Sky colour?", Options: []string{"Blue", "Green"}, Answer: 0}},
+		},
+		{
+			name:    "unknown answer",
+			input:   "What is 2+2?,3,4,9\n",
+			wantErr: true,
+		},
+		{
+			name:    "too few options",
+			input:   "Lonely?,1\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bank, err := ParseCSV("test", strings.NewReader(tt.input))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", bank.Questions)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(bank.Questions, tt.want) {
+				t.Errorf("got %+v, want %+v", bank.Questions, tt.want)
+			}
+		})
+	}
+}
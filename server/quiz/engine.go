@@ -0,0 +1,108 @@
+package quiz
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Engine hands out questions to players and grades their answers.
+// Each player has at most one pending question at a time, and questions
+// are not repeated for a player until the whole bank has been used.
+type Engine struct {
+	bank    *Bank
+	pending map[string]*Question       // playerID -> question awaiting an answer
+	asked   map[string]map[string]bool // playerID -> question IDs already asked
+	rng     *rand.Rand
+	mutex   sync.Mutex
+}
+
+// Result is the outcome of a graded answer
+type Result struct {
+	QuestionID    string `json:"questionId"`
+	Correct       bool   `json:"correct"`
+	CorrectAnswer string `json:"correctAnswer,omitempty"` // Only revealed to players who got it right
+}
+
+// NewEngine creates an engine that serves questions from bank
+func NewEngine(bank *Bank) *Engine {
+	return &Engine{
+		bank:    bank,
+		pending: make(map[string]*Question),
+		asked:   make(map[string]map[string]bool),
+		rng:     rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// BankName returns the name of the bank this engine serves from
+func (e *Engine) BankName() string {
+	return e.bank.Name
+}
+
+// Next picks a question for the player and marks it as pending.
+// Asking again before answering returns the same question, so players
+// can't reroll until they get one they know.
+func (e *Engine) Next(playerID string) (PublicQuestion, error) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if len(e.bank.Questions) == 0 {
+		return PublicQuestion{}, ErrEmptyBank
+	}
+
+	if q, exists := e.pending[playerID]; exists {
+		return q.Public(), nil
+	}
+
+	asked := e.asked[playerID]
+	if asked == nil || len(asked) >= len(e.bank.Questions) {
+		// First question or bank exhausted - start a fresh cycle
+		asked = make(map[string]bool)
+		e.asked[playerID] = asked
+	}
+
+	var candidates []*Question
+	for i := range e.bank.Questions {
+		if !asked[e.bank.Questions[i].ID] {
+			candidates = append(candidates, &e.bank.Questions[i])
+		}
+	}
+
+	q := candidates[e.rng.Intn(len(candidates))]
+	asked[q.ID] = true
+	e.pending[playerID] = q
+
+	return q.Public(), nil
+}
+
+// Submit grades the player's answer to their pending question
+func (e *Engine) Submit(playerID, questionID, answer string) (Result, error) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	q, exists := e.pending[playerID]
+	if !exists {
+		return Result{}, ErrNoPendingQuestion
+	}
+	if q.ID != questionID {
+		return Result{}, ErrWrongQuestion
+	}
+
+	delete(e.pending, playerID)
+
+	// Telling wrong guessers the answer would let a script learn the bank
+	result := Result{QuestionID: q.ID, Correct: q.IsCorrect(answer)}
+	if result.Correct {
+		result.CorrectAnswer = q.AnswerKey()
+	}
+	return result, nil
+}
+
+// Forget drops all quiz state for a player
+func (e *Engine) Forget(playerID string) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	delete(e.pending, playerID)
+	delete(e.asked, playerID)
+}
@@ -0,0 +1,191 @@
+package quiz
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// giftWeight matches a "%50%" style weight prefix on a GIFT answer
+var giftWeight = regexp.MustCompile(`^%(-?[0-9.]+)%`)
+
+// ParseGIFT parses the Moodle GIFT text format. Multiple-choice and
+// true/false questions are supported:
+//
+//	// comment
+//	::Title:: What is 2 + 2? {
+//	  =4
+//	  ~3 #feedback
+//	  ~5
+//	}
+//	The sky is blue. {T}
+//
+// Questions are separated by blank lines. "~%100%" marks a correct answer
+// the same way "=" does.
+func ParseGIFT(name string, r io.Reader) (*Bank, error) {
+	var drafts []draftQuestion
+	var block []string
+	blockStart := 0
+
+	flush := func() {
+		if len(block) == 0 {
+			return
+		}
+		drafts = append(drafts, parseGIFTQuestion(strings.Join(block, "\n"), blockStart))
+		block = nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		trimmed := strings.TrimSpace(text)
+
+		switch {
+		case strings.HasPrefix(trimmed, "//"):
+			continue
+		case strings.HasPrefix(trimmed, "$CATEGORY:"):
+			continue
+		case trimmed == "":
+			flush()
+			continue
+		}
+
+		if len(block) == 0 {
+			blockStart = line
+		}
+		block = append(block, text)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", name, err)
+	}
+	flush()
+
+	return buildBank(name, drafts)
+}
+
+// parseGIFTQuestion parses a single question block
+func parseGIFTQuestion(block string, line int) draftQuestion {
+	draft := draftQuestion{Source: fmt.Sprintf("line %d", line)}
+
+	// Optional "::title::" prefix becomes the question ID
+	text := strings.TrimSpace(block)
+	if strings.HasPrefix(text, "::") {
+		if end := strings.Index(text[2:], "::"); end >= 0 {
+			draft.ID = strings.TrimSpace(text[2 : 2+end])
+			text = text[4+end:]
+		}
+	}
+
+	start := giftIndex(text, '{')
+	end := -1
+	if start >= 0 {
+		end = giftIndex(text[start:], '}')
+	}
+	if start < 0 || end < 0 {
+		// No answer block - buildBank will report the missing options
+		draft.Text = giftText(text)
+		return draft
+	}
+	end += start
+
+	// Text after the answers (fill-in-the-blank style) is kept as part of the question
+	draft.Text = giftText(text[:start] + " " + text[end+1:])
+	answers := strings.TrimSpace(text[start+1 : end])
+
+	switch strings.ToUpper(answers) {
+	case "T", "TRUE":
+		draft.Choices = []Choice{{Text: "True", Correct: true}, {Text: "False"}}
+		return draft
+	case "F", "FALSE":
+		draft.Choices = []Choice{{Text: "True"}, {Text: "False", Correct: true}}
+		return draft
+	}
+
+	for _, answer := range giftSplitAnswers(answers) {
+		correct := answer[0] == '='
+		body := strings.TrimSpace(answer[1:])
+
+		if m := giftWeight.FindStringSubmatch(body); m != nil {
+			weight, err := strconv.ParseFloat(m[1], 64)
+			correct = err == nil && weight == 100
+			body = body[len(m[0]):]
+		}
+
+		// Drop per-answer feedback
+		if hash := giftIndex(body, '#'); hash >= 0 {
+			body = body[:hash]
+		}
+
+		draft.Choices = append(draft.Choices, Choice{
+			Text:    giftText(body),
+			Correct: correct,
+		})
+	}
+
+	return draft
+}
+
+// giftIndex returns the index of the first unescaped c in s, or -1
+func giftIndex(s string, c byte) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+			continue
+		}
+		if s[i] == c {
+			return i
+		}
+	}
+	return -1
+}
+
+// giftSplitAnswers splits an answer block on unescaped '=' and '~' markers
+func giftSplitAnswers(s string) []string {
+	var answers []string
+	start := -1
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+			continue
+		}
+		if s[i] == '=' || s[i] == '~' {
+			if start >= 0 {
+				answers = append(answers, s[start:i])
+			}
+			start = i
+		}
+	}
+	if start >= 0 {
+		answers = append(answers, s[start:])
+	}
+	return answers
+}
+
+// giftText strips the optional [format] tag, unescapes and trims GIFT text
+func giftText(s string) string {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "[") {
+		if end := strings.Index(s, "]"); end >= 0 {
+			switch s[1:end] {
+			case "html", "moodle", "plain", "markdown":
+				s = s[end+1:]
+			}
+		}
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			if s[i] == 'n' {
+				b.WriteByte('\n')
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
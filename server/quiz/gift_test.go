@@ -0,0 +1,71 @@
+package quiz
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseGIFT(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []Question
+		wantErr bool
+	}{
+		{
+			name:  "multiple choice with title and feedback",
+			input: "// comment\n::sum:: What is 2 + 2? {\n  =4\n  ~3 #close\n  ~5\n}\n",
+			want:  []Question{{ID: "sum", Text: "What is 2 + 2?", Options: []string{"4", "3", "5"}, Answer: 0}},
+		},
+		{
+			name:  "true false",
+			input: "The sky is blue. {T}\n\nFire is cold. {FALSE}\n",
+			want: []Question{
+				{ID: "q1", Text: "The sky is blue.", Options: []string{"True", "False"}, Answer: 0},
+				{ID: "q2", Text: "Fire is cold.", Options: []string{"True", "False"}, Answer: 1},
+			},
+		},
+		{
+			name:  "weights",
+			input: "Pick one {~%100%yes ~%-50%no}\n\nPick again {~%100.0%yes ~%50%no}\n",
+			want: []Question{
+				{ID: "q1", Text: "Pick one", Options: []string{"yes", "no"}, Answer: 0},
+				{ID: "q2", Text: "Pick again", Options: []string{"yes", "no"}, Answer: 0},
+			},
+		},
+		{
+			name:  "escaped braces",
+			input: `What wraps a GIFT answer? {=\{ and \} ~( and )}` + "\n",
+			want:  []Question{{ID: "q1", Text: "What wraps a GIFT answer?", Options: []string{"{ and }", "( and )"}, Answer: 0}},
+		},
+		{
+			name:    "no correct answer",
+			input:   "Pick one {~yes ~no}\n",
+			wantErr: true,
+		},
+		{
+			name:    "no answer block",
+			input:   "Just some text\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bank, err := ParseGIFT("test", strings.NewReader(tt.input))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", bank.Questions)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(bank.Questions, tt.want) {
+				t.Errorf("got %+v, want %+v", bank.Questions, tt.want)
+			}
+		})
+	}
+}
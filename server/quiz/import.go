@@ -0,0 +1,46 @@
+package quiz
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// Supported question bank formats
+const (
+	FormatJSON   = "json"   // questions.json array used by the client
+	FormatCSV    = "csv"    // Spreadsheet export
+	FormatGIFT   = "gift"   // Moodle GIFT text format
+	FormatMoodle = "moodle" // Moodle XML export
+)
+
+// FormatFromPath guesses a bank format from a file extension
+func FormatFromPath(path string) (string, bool) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return FormatJSON, true
+	case ".csv":
+		return FormatCSV, true
+	case ".gift", ".txt":
+		return FormatGIFT, true
+	case ".xml":
+		return FormatMoodle, true
+	}
+	return "", false
+}
+
+// Parse reads a question bank in the given format and validates it
+func Parse(name, format string, r io.Reader) (*Bank, error) {
+	switch strings.ToLower(format) {
+	case FormatJSON:
+		return ParseJSON(name, r)
+	case FormatCSV:
+		return ParseCSV(name, r)
+	case FormatGIFT:
+		return ParseGIFT(name, r)
+	case FormatMoodle, "xml":
+		return ParseMoodleXML(name, r)
+	}
+	return nil, fmt.Errorf("unsupported question bank format %q", format)
+}
@@ -0,0 +1,164 @@
+package quiz
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBankName is the bank used when a room doesn't choose one
+const DefaultBankName = "default"
+
+//go:embed banks/*.json
+var embeddedBanks embed.FS
+
+// Library holds every question bank the server knows about
+type Library struct {
+	banks map[string]*Bank
+	mutex sync.RWMutex
+}
+
+// jsonQuestion is the legacy questions.json format shipped with the client
+type jsonQuestion struct {
+	QuestionNumber int                    `json:"question_number"`
+	Question       string                 `json:"question"`
+	Options        map[string]interface{} `json:"options"`
+	CorrectAnswer  int                    `json:"correct_answer"`
+}
+
+// ParseJSON parses a bank in the questions.json format
+func ParseJSON(name string, r io.Reader) (*Bank, error) {
+	var raw []jsonQuestion
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", name, err)
+	}
+
+	drafts := make([]draftQuestion, 0, len(raw))
+	for i, rq := range raw {
+		draft := draftQuestion{
+			Source: fmt.Sprintf("question %d", i+1),
+			Text:   rq.Question,
+		}
+		if rq.QuestionNumber != 0 {
+			draft.ID = strconv.Itoa(rq.QuestionNumber)
+			draft.Source = fmt.Sprintf("question_number %d", rq.QuestionNumber)
+		}
+
+		// Options are keyed "1".."n" - sort them numerically
+		keys := make([]int, 0, len(rq.Options))
+		for key := range rq.Options {
+			n, err := strconv.Atoi(key)
+			if err != nil {
+				return nil, fmt.Errorf("%s: invalid option key %q", draft.Source, key)
+			}
+			keys = append(keys, n)
+		}
+		sort.Ints(keys)
+
+		for _, key := range keys {
+			draft.Choices = append(draft.Choices, Choice{
+				Text:    fmt.Sprint(rq.Options[strconv.Itoa(key)]),
+				Correct: key == rq.CorrectAnswer,
+			})
+		}
+		drafts = append(drafts, draft)
+	}
+
+	return buildBank(name, drafts)
+}
+
+// NewLibrary loads the embedded banks plus any banks found in dir.
+// dir may be empty, in which case only the embedded banks are loaded.
+func NewLibrary(dir string) (*Library, error) {
+	lib := &Library{banks: make(map[string]*Bank)}
+
+	entries, err := embeddedBanks.ReadDir("banks")
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		f, err := embeddedBanks.Open("banks/" + entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		bank, err := ParseJSON(bankName(entry.Name()), f)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+		lib.Add(bank)
+	}
+
+	if dir == "" {
+		return lib, nil
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		format, ok := FormatFromPath(file.Name())
+		if file.IsDir() || !ok {
+			continue
+		}
+
+		path := filepath.Join(dir, file.Name())
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		bank, err := Parse(bankName(path), format, f)
+		f.Close()
+		if err != nil {
+			// One bad file shouldn't take the whole server down
+			log.Printf("Skipping question bank %s: %v", path, err)
+			continue
+		}
+		lib.Add(bank)
+	}
+
+	return lib, nil
+}
+
+// bankName derives a bank name from its file name
+func bankName(path string) string {
+	base := filepath.Base(path)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// Add registers a bank, replacing any bank with the same name
+func (l *Library) Add(bank *Bank) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.banks[bank.Name] = bank
+}
+
+// Get returns the bank with the given name
+func (l *Library) Get(name string) (*Bank, bool) {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	bank, exists := l.banks[name]
+	return bank, exists
+}
+
+// Names returns the names of all loaded banks, sorted
+func (l *Library) Names() []string {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	names := make([]string, 0, len(l.banks))
+	for name := range l.banks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,97 @@
+package quiz
+
+import (
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// htmlTag matches HTML tags in Moodle's rich text fields
+var htmlTag = regexp.MustCompile(`<[^>]*>`)
+
+// moodleQuiz mirrors the parts of a Moodle XML export we use
+type moodleQuiz struct {
+	Questions []moodleQuestion `xml:"question"`
+}
+
+type moodleQuestion struct {
+	Type         string         `xml:"type,attr"`
+	Name         string         `xml:"name>text"`
+	QuestionText string         `xml:"questiontext>text"`
+	Answers      []moodleAnswer `xml:"answer"`
+}
+
+type moodleAnswer struct {
+	Fraction string `xml:"fraction,attr"`
+	Text     string `xml:"text"`
+}
+
+// ParseMoodleXML parses a Moodle XML quiz export. Multichoice and
+// truefalse questions are imported; category and description entries are
+// skipped, and any other question type is reported as invalid.
+func ParseMoodleXML(name string, r io.Reader) (*Bank, error) {
+	var doc moodleQuiz
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", name, err)
+	}
+
+	var drafts []draftQuestion
+	var unsupported []string
+	for i, mq := range doc.Questions {
+		source := fmt.Sprintf("question %d", i+1)
+		if mq.Name != "" {
+			source = fmt.Sprintf("question %q", moodleText(mq.Name))
+		}
+
+		switch mq.Type {
+		case "category", "description":
+			continue
+		case "multichoice", "truefalse":
+		default:
+			unsupported = append(unsupported, fmt.Sprintf("%s: unsupported type %q", source, mq.Type))
+			continue
+		}
+
+		draft := draftQuestion{
+			Source: source,
+			ID:     moodleText(mq.Name),
+			Text:   moodleText(mq.QuestionText),
+		}
+		for _, answer := range mq.Answers {
+			// Only a full-credit answer counts as correct; partial credit
+			// answers make the question multi-answer, which buildBank rejects
+			fraction, _ := strconv.ParseFloat(answer.Fraction, 64)
+			text := moodleText(answer.Text)
+			if mq.Type == "truefalse" && text != "" {
+				// Exports use "true"/"false"; match the GIFT parser's casing
+				text = strings.ToUpper(text[:1]) + strings.ToLower(text[1:])
+			}
+			draft.Choices = append(draft.Choices, Choice{
+				Text:    text,
+				Correct: fraction >= 100,
+			})
+		}
+		drafts = append(drafts, draft)
+	}
+
+	bank, err := buildBank(name, drafts)
+	if len(unsupported) > 0 {
+		if verr, ok := err.(*ValidationError); ok {
+			verr.Problems = append(unsupported, verr.Problems...)
+			return nil, verr
+		}
+		return nil, &ValidationError{Bank: name, Problems: unsupported}
+	}
+	return bank, err
+}
+
+// moodleText converts Moodle rich text to plain text
+func moodleText(s string) string {
+	s = htmlTag.ReplaceAllString(s, " ")
+	s = html.UnescapeString(s)
+	return strings.Join(strings.Fields(s), " ")
+}
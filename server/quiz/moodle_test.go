@@ -0,0 +1,87 @@
+package quiz
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseMoodleXML(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []Question
+		wantErr bool
+	}{
+		{
+			name: "multichoice with html",
+			input: `<quiz>
+  <question type="category"><category><text>$course$/Maths</text></category></question>
+  <question type="multichoice">
+    <name><text>sum</text></name>
+    <questiontext format="html"><text><![CDATA[<p>What is 2 &amp; 2?</p>]]></text></questiontext>
+    <answer fraction="0"><text>3</text></answer>
+    <answer fraction="100"><text><![CDATA[<b>4</b>]]></text></answer>
+  </question>
+</quiz>`,
+			want: []Question{{ID: "sum", Text: "What is 2 & 2?", Options: []string{"3", "4"}, Answer: 1}},
+		},
+		{
+			name: "truefalse",
+			input: `<quiz>
+  <question type="truefalse">
+    <name><text>sky</text></name>
+    <questiontext><text>The sky is blue.</text></questiontext>
+    <answer fraction="100"><text>true</text></answer>
+    <answer fraction="0"><text>false</text></answer>
+  </question>
+</quiz>`,
+			want: []Question{{ID: "sky", Text: "The sky is blue.", Options: []string{"True", "False"}, Answer: 0}},
+		},
+		{
+			name: "partial credit only",
+			input: `<quiz>
+  <question type="multichoice">
+    <name><text>half</text></name>
+    <questiontext><text>Pick one</text></questiontext>
+    <answer fraction="50"><text>a</text></answer>
+    <answer fraction="50"><text>b</text></answer>
+  </question>
+</quiz>`,
+			wantErr: true,
+		},
+		{
+			name: "unsupported type",
+			input: `<quiz>
+  <question type="essay">
+    <name><text>essay</text></name>
+    <questiontext><text>Discuss.</text></questiontext>
+  </question>
+</quiz>`,
+			wantErr: true,
+		},
+		{
+			name:    "malformed xml",
+			input:   `<quiz><question>`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bank, err := ParseMoodleXML("test", strings.NewReader(tt.input))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", bank.Questions)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(bank.Questions, tt.want) {
+				t.Errorf("got %+v, want %+v", bank.Questions, tt.want)
+			}
+		})
+	}
+}
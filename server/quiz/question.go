@@ -0,0 +1,63 @@
+package quiz
+
+import (
+	"errors"
+	"strconv"
+)
+
+// Question is a multiple-choice question held by the server.
+// The correct answer never leaves the server until the player has answered.
+type Question struct {
+	ID      string
+	Text    string
+	Options []string
+	Answer  int // Index into Options
+}
+
+// Bank is a named set of questions
+type Bank struct {
+	Name      string
+	Questions []Question
+}
+
+// PublicQuestion is the player-facing view of a question (no answer).
+// Options are keyed "1".."n" like the original questions.json.
+type PublicQuestion struct {
+	ID       string            `json:"id"`
+	Question string            `json:"question"`
+	Options  map[string]string `json:"options"`
+}
+
+var (
+	ErrEmptyBank         = errors.New("question bank is empty")
+	ErrNoPendingQuestion = errors.New("no question pending for player")
+	ErrWrongQuestion     = errors.New("answer is for a different question")
+)
+
+// optionKey converts an option index to its 1-based key
+func optionKey(index int) string {
+	return strconv.Itoa(index + 1)
+}
+
+// Public returns the question without its answer
+func (q Question) Public() PublicQuestion {
+	options := make(map[string]string, len(q.Options))
+	for i, option := range q.Options {
+		options[optionKey(i)] = option
+	}
+	return PublicQuestion{
+		ID:       q.ID,
+		Question: q.Text,
+		Options:  options,
+	}
+}
+
+// AnswerKey returns the option key of the correct answer
+func (q Question) AnswerKey() string {
+	return optionKey(q.Answer)
+}
+
+// IsCorrect reports whether the submitted option key is the correct answer
+func (q Question) IsCorrect(key string) bool {
+	return key == q.AnswerKey()
+}
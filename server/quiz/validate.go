@@ -0,0 +1,124 @@
+package quiz
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Choice is one answer option as read from an import file
+type Choice struct {
+	Text    string
+	Correct bool
+}
+
+// draftQuestion is a question as parsed, before validation.
+// Parsers produce drafts so every format goes through the same checks.
+type draftQuestion struct {
+	Source  string // Where the question came from, for error messages
+	ID      string
+	Text    string
+	Choices []Choice
+}
+
+// ValidationError lists every problem found in a question bank
+type ValidationError struct {
+	Bank     string
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("question bank %s is invalid: %s", e.Bank, strings.Join(e.Problems, "; "))
+}
+
+// normalize collapses whitespace and case so near-identical text compares equal
+func normalize(text string) string {
+	return strings.ToLower(strings.Join(strings.Fields(text), " "))
+}
+
+// buildBank validates drafts and converts them to a Bank.
+// Each question needs non-empty text, at least two distinct non-empty
+// options and exactly one correct option; duplicate questions are rejected.
+func buildBank(name string, drafts []draftQuestion) (*Bank, error) {
+	bank := &Bank{Name: name}
+	var problems []string
+	seenText := make(map[string]string) // normalized text -> source
+	seenID := make(map[string]bool)
+
+	// Generated IDs must not take an ID a later question asks for
+	explicitID := make(map[string]bool)
+	for _, draft := range drafts {
+		if draft.ID != "" {
+			explicitID[draft.ID] = true
+		}
+	}
+
+	for i, draft := range drafts {
+		source := draft.Source
+		if source == "" {
+			source = fmt.Sprintf("question %d", i+1)
+		}
+		var issues []string
+
+		text := strings.TrimSpace(draft.Text)
+		if text == "" {
+			issues = append(issues, "empty question text")
+		} else if first, exists := seenText[normalize(text)]; exists {
+			issues = append(issues, fmt.Sprintf("duplicate of %s", first))
+		} else {
+			seenText[normalize(text)] = source
+		}
+
+		if len(draft.Choices) < 2 {
+			issues = append(issues, fmt.Sprintf("needs at least 2 options, has %d", len(draft.Choices)))
+		}
+
+		q := Question{ID: draft.ID, Text: text, Answer: -1}
+		correctCount := 0
+		seenOption := make(map[string]bool)
+		for _, choice := range draft.Choices {
+			option := strings.TrimSpace(choice.Text)
+			if option == "" {
+				issues = append(issues, "empty option")
+				continue
+			}
+			if seenOption[normalize(option)] {
+				issues = append(issues, fmt.Sprintf("duplicate option %q", option))
+				continue
+			}
+			seenOption[normalize(option)] = true
+
+			if choice.Correct {
+				correctCount++
+				q.Answer = len(q.Options)
+			}
+			q.Options = append(q.Options, option)
+		}
+		if correctCount != 1 {
+			issues = append(issues, fmt.Sprintf("needs exactly 1 correct option, has %d", correctCount))
+		}
+
+		// Fall back to a positional ID, keeping IDs unique within the bank
+		if q.ID == "" || seenID[q.ID] {
+			q.ID = fmt.Sprintf("q%d", i+1)
+			for n := 2; explicitID[q.ID] || seenID[q.ID]; n++ {
+				q.ID = fmt.Sprintf("q%d-%d", i+1, n)
+			}
+		}
+		seenID[q.ID] = true
+
+		if len(issues) > 0 {
+			problems = append(problems, fmt.Sprintf("%s: %s", source, strings.Join(issues, ", ")))
+			continue
+		}
+		bank.Questions = append(bank.Questions, q)
+	}
+
+	if len(drafts) == 0 {
+		problems = append(problems, "no questions found")
+	}
+	if len(problems) > 0 {
+		return nil, &ValidationError{Bank: name, Problems: problems}
+	}
+
+	return bank, nil
+}
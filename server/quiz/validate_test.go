@@ -0,0 +1,38 @@
+package quiz
+
+import (
+	"testing"
+)
+
+func TestBuildBankIDs(t *testing.T) {
+	choices := []Choice{{Text: "yes", Correct: true}, {Text: "no"}}
+	tests := []struct {
+		name string
+		ids  []string
+		want []string
+	}{
+		{"explicit IDs kept", []string{"a", "b"}, []string{"a", "b"}},
+		{"missing IDs are positional", []string{"", ""}, []string{"q1", "q2"}},
+		{"duplicate ID replaced", []string{"a", "a"}, []string{"a", "q2"}},
+		{"generated ID avoids a later explicit one", []string{"", "q1"}, []string{"q1-2", "q1"}},
+		{"generated ID avoids an earlier explicit one", []string{"q2", ""}, []string{"q2", "q2-2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var drafts []draftQuestion
+			for i, id := range tt.ids {
+				drafts = append(drafts, draftQuestion{ID: id, Text: string(rune('A' + i)), Choices: choices})
+			}
+			bank, err := buildBank("test", drafts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, q := range bank.Questions {
+				if q.ID != tt.want[i] {
+					t.Errorf("question %d: got ID %q, want %q", i, q.ID, tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// droppedMessages counts rate-limited messages across all connections by
+// type. Kept in process rather than served, so it only shows up in logs.
+var droppedMessages = &dropCounter{counts: make(map[string]int64)}
+
+// dropCounter is a map of counts safe to share between connections
+type dropCounter struct {
+	mutex  sync.Mutex
+	counts map[string]int64
+}
+
+func (d *dropCounter) add(key string) {
+	d.mutex.Lock()
+	d.counts[key]++
+	d.mutex.Unlock()
+}
+
+// totals returns a copy of the counts
+func (d *dropCounter) totals() map[string]int64 {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	totals := make(map[string]int64, len(d.counts))
+	for key, n := range d.counts {
+		totals[key] = n
+	}
+	return totals
+}
+
+// RateLimit is a token bucket: Rate messages per second on average,
+// with bursts of up to Burst messages
+type RateLimit struct {
+	Rate  float64
+	Burst float64
+}
+
+// LimitConfig controls how much a single connection may send
+type LimitConfig struct {
+	MaxMessageBytes int64                // Largest frame read from a client
+	Default         RateLimit            // For message types without their own limit
+	PerType         map[string]RateLimit // By message type
+	MaxDrops        int                  // Dropped messages tolerated per DropWindow
+	DropWindow      time.Duration
+}
+
+// DefaultLimitConfig returns limits comfortably above what the game client sends
+func DefaultLimitConfig() LimitConfig {
+	return LimitConfig{
+		MaxMessageBytes: 1 << 20, // Room for an uploaded question bank
+		Default:         RateLimit{Rate: 10, Burst: 20},
+		PerType: map[string]RateLimit{
+			"updatePosition": {Rate: 90, Burst: 120},  // Sent every frame
+			"snapshotAck":    {Rate: 150, Burst: 200}, // One per gameUpdate
+			"bulletSpawn":    {Rate: 15, Burst: 10},   // Uzi fires 10/s, shotgun 3 at once
+			"bulletDestroy":  {Rate: 20, Burst: 20},
+			"createRoom":     {Rate: 0.2, Burst: 3},
+			"joinRoom":       {Rate: 1, Burst: 5},
+			"rejoinRoom":     {Rate: 1, Burst: 5},
+		},
+		MaxDrops:   200,
+		DropWindow: 10 * time.Second,
+	}
+}
+
+// loadLimitConfig reads overrides from the environment:
+//
+//	MAX_MESSAGE_BYTES=65536
+//	RATE_LIMITS=updatePosition=60:90,bulletSpawn=10:10,default=5:10
+func loadLimitConfig() LimitConfig {
+	cfg := DefaultLimitConfig()
+
+	if value := os.Getenv("MAX_MESSAGE_BYTES"); value != "" {
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil && n > 0 {
+			cfg.MaxMessageBytes = n
+		} else {
+			log.Printf("Ignoring invalid MAX_MESSAGE_BYTES=%q", value)
+		}
+	}
+
+	for _, entry := range strings.Split(os.Getenv("RATE_LIMITS"), ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		msgType, limit, err := parseRateLimit(entry)
+		if err != nil {
+			log.Printf("Ignoring invalid RATE_LIMITS entry %q", entry)
+			continue
+		}
+		if msgType == "default" {
+			cfg.Default = limit
+		} else {
+			cfg.PerType[msgType] = limit
+		}
+	}
+
+	return cfg
+}
+
+// parseRateLimit parses a "type=rate:burst" entry
+func parseRateLimit(entry string) (string, RateLimit, error) {
+	msgType, value, _ := strings.Cut(strings.TrimSpace(entry), "=")
+	rateText, burstText, _ := strings.Cut(value, ":")
+
+	rate, err := strconv.ParseFloat(rateText, 64)
+	if err != nil || rate <= 0 {
+		return "", RateLimit{}, strconv.ErrSyntax
+	}
+	burst, err := strconv.ParseFloat(burstText, 64)
+	if err != nil || burst < 1 {
+		return "", RateLimit{}, strconv.ErrSyntax
+	}
+	return msgType, RateLimit{Rate: rate, Burst: burst}, nil
+}
+
+// tokenBucket refills at limit.Rate tokens per second up to limit.Burst
+type tokenBucket struct {
+	limit  RateLimit
+	tokens float64
+	last   time.Time
+}
+
+// allow takes a token if one is available
+func (b *tokenBucket) allow(now time.Time) bool {
+	b.tokens += now.Sub(b.last).Seconds() * b.limit.Rate
+	if b.tokens > b.limit.Burst {
+		b.tokens = b.limit.Burst
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// rateLimiter tracks one connection's buckets and dropped messages.
+// Only used from the connection's readPump, so it needs no locking.
+type rateLimiter struct {
+	cfg         LimitConfig
+	buckets     map[string]*tokenBucket
+	dropped     map[string]int // Totals by bucket
+	windowStart time.Time
+	windowDrops int
+}
+
+func newRateLimiter(cfg LimitConfig) *rateLimiter {
+	return &rateLimiter{
+		cfg:     cfg,
+		buckets: make(map[string]*tokenBucket),
+		dropped: make(map[string]int),
+	}
+}
+
+// allow reports whether a message of msgType may be handled now.
+// exceeded is true once the client has dropped too many messages
+// in the current window and should be disconnected.
+func (l *rateLimiter) allow(msgType string) (ok bool, exceeded bool) {
+	now := time.Now()
+
+	// Types without their own limit share one bucket, so made-up
+	// types can't grow the maps
+	key := msgType
+	limit, configured := l.cfg.PerType[msgType]
+	if !configured {
+		key, limit = "other", l.cfg.Default
+	}
+
+	bucket, exists := l.buckets[key]
+	if !exists {
+		bucket = &tokenBucket{limit: limit, tokens: limit.Burst, last: now}
+		l.buckets[key] = bucket
+	}
+
+	if bucket.allow(now) {
+		return true, false
+	}
+
+	l.dropped[key]++
+	droppedMessages.add(key)
+	if now.Sub(l.windowStart) > l.cfg.DropWindow {
+		l.windowStart = now
+		l.windowDrops = 0
+	}
+	l.windowDrops++
+	return false, l.windowDrops > l.cfg.MaxDrops
+}
+
+// totalDropped returns how many messages have been dropped so far
+func (l *rateLimiter) totalDropped() int {
+	total := 0
+	for _, n := range l.dropped {
+		total += n
+	}
+	return total
+}
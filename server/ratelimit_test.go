@@ -0,0 +1,122 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTokenBucket(t *testing.T) {
+	start := time.Unix(0, 0)
+	limit := RateLimit{Rate: 10, Burst: 3}
+
+	tests := []struct {
+		name  string
+		steps []time.Duration // Offsets from start of each attempt
+		want  []bool
+	}{
+		{"burst", []time.Duration{0, 0, 0, 0}, []bool{true, true, true, false}},
+		{"refill one token", []time.Duration{0, 0, 0, 0, 100 * time.Millisecond, 100 * time.Millisecond}, []bool{true, true, true, false, true, false}},
+		{"partial refill", []time.Duration{0, 0, 0, 50 * time.Millisecond, 100 * time.Millisecond}, []bool{true, true, true, false, true}},
+		{"refill capped at burst", []time.Duration{0, 0, 0, time.Minute, time.Minute, time.Minute, time.Minute}, []bool{true, true, true, true, true, true, false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &tokenBucket{limit: limit, tokens: limit.Burst, last: start}
+			for i, offset := range tt.steps {
+				if got := b.allow(start.Add(offset)); got != tt.want[i] {
+					t.Errorf("attempt %d at %v: got %v, want %v", i, offset, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	cfg := LimitConfig{
+		Default:    RateLimit{Rate: 0.001, Burst: 2},
+		PerType:    map[string]RateLimit{"bulletSpawn": {Rate: 0.001, Burst: 1}},
+		MaxDrops:   2,
+		DropWindow: time.Minute,
+	}
+
+	tests := []struct {
+		name         string
+		types        []string
+		wantAllowed  []bool
+		wantExceeded []bool
+		wantDropped  map[string]int
+	}{
+		{
+			"configured type has its own bucket",
+			[]string{"bulletSpawn", "bulletSpawn", "chat"},
+			[]bool{true, false, true},
+			[]bool{false, false, false},
+			map[string]int{"bulletSpawn": 1},
+		},
+		{
+			"unconfigured types share one bucket",
+			[]string{"chat", "made-up", "another"},
+			[]bool{true, true, false},
+			[]bool{false, false, false},
+			map[string]int{"other": 1},
+		},
+		{
+			"too many drops in a window",
+			[]string{"bulletSpawn", "bulletSpawn", "bulletSpawn", "bulletSpawn"},
+			[]bool{true, false, false, false},
+			[]bool{false, false, false, true},
+			map[string]int{"bulletSpawn": 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newRateLimiter(cfg)
+			for i, msgType := range tt.types {
+				allowed, exceeded := l.allow(msgType)
+				if allowed != tt.wantAllowed[i] || exceeded != tt.wantExceeded[i] {
+					t.Errorf("message %d (%s): got allowed %v exceeded %v, want %v %v",
+						i, msgType, allowed, exceeded, tt.wantAllowed[i], tt.wantExceeded[i])
+				}
+			}
+			if len(l.dropped) != len(tt.wantDropped) {
+				t.Errorf("dropped %v, want %v", l.dropped, tt.wantDropped)
+			}
+			for key, n := range tt.wantDropped {
+				if l.dropped[key] != n {
+					t.Errorf("dropped %v, want %v", l.dropped, tt.wantDropped)
+				}
+			}
+		})
+	}
+}
+
+func TestParseRateLimit(t *testing.T) {
+	tests := []struct {
+		entry    string
+		wantType string
+		want     RateLimit
+		wantErr  bool
+	}{
+		{"updatePosition=60:90", "updatePosition", RateLimit{Rate: 60, Burst: 90}, false},
+		{" default=0.5:1 ", "default", RateLimit{Rate: 0.5, Burst: 1}, false},
+		{"bulletSpawn=10", "", RateLimit{}, true},
+		{"bulletSpawn=0:10", "", RateLimit{}, true},
+		{"bulletSpawn=10:0.5", "", RateLimit{}, true},
+		{"bulletSpawn=fast:10", "", RateLimit{}, true},
+		{"", "", RateLimit{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.entry, func(t *testing.T) {
+			msgType, limit, err := parseRateLimit(tt.entry)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if msgType != tt.wantType || limit != tt.want {
+				t.Errorf("got %q %+v, want %q %+v", msgType, limit, tt.wantType, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"log"
+	"os"
+	"time"
+)
+
+// ReaperConfig controls when abandoned rooms are garbage collected
+type ReaperConfig struct {
+	Interval    time.Duration // How often rooms are checked
+	IdleTimeout time.Duration // No players and no dashboard for this long
+	EndedGrace  time.Duration // Time a room may sit in ended/results
+	MaxLifetime time.Duration // Hard cap on a room's age
+}
+
+// DefaultReaperConfig returns the default room expiry rules
+func DefaultReaperConfig() ReaperConfig {
+	return ReaperConfig{
+		Interval:    30 * time.Second,
+		IdleTimeout: 10 * time.Minute,
+		EndedGrace:  30 * time.Minute,
+		MaxLifetime: 6 * time.Hour,
+	}
+}
+
+// loadReaperConfig reads overrides such as ROOM_IDLE_TIMEOUT=5m from the environment
+func loadReaperConfig() ReaperConfig {
+	cfg := DefaultReaperConfig()
+	envDuration("ROOM_REAP_INTERVAL", &cfg.Interval)
+	envDuration("ROOM_IDLE_TIMEOUT", &cfg.IdleTimeout)
+	envDuration("ROOM_ENDED_GRACE", &cfg.EndedGrace)
+	envDuration("ROOM_MAX_LIFETIME", &cfg.MaxLifetime)
+	return cfg
+}
+
+// envDuration overwrites *d with the duration in the named variable, if set and valid
+func envDuration(name string, d *time.Duration) {
+	value := os.Getenv(name)
+	if value == "" {
+		return
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil || parsed <= 0 {
+		log.Printf("Ignoring invalid %s=%q", name, value)
+		return
+	}
+	*d = parsed
+}
+
+// expiryReason returns why the room should be removed, or "" to keep it
+func (r *Room) expiryReason(now time.Time, cfg ReaperConfig) string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	switch {
+	case now.Sub(r.Created) > cfg.MaxLifetime:
+		return "Room reached its maximum lifetime"
+	case !r.emptySince.IsZero() && now.Sub(r.emptySince) > cfg.IdleTimeout:
+		return "Room was idle"
+	case !r.endedAt.IsZero() && now.Sub(r.endedAt) > cfg.EndedGrace:
+		return "Game has ended"
+	}
+	return ""
+}
+
+// reap periodically removes expired rooms until stop is closed.
+// A nil stop runs for the life of the process.
+func (rm *RoomManager) reap(cfg ReaperConfig, stop <-chan struct{}) {
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			rm.sweep(now, cfg)
+		case <-stop:
+			return
+		}
+	}
+}
+
+// sweep removes the rooms that have expired as of now
+func (rm *RoomManager) sweep(now time.Time, cfg ReaperConfig) {
+	rm.mutex.RLock()
+	rooms := make([]*Room, 0, len(rm.rooms))
+	for _, room := range rm.rooms {
+		rooms = append(rooms, room)
+	}
+	rm.mutex.RUnlock()
+
+	for _, room := range rooms {
+		if reason := room.expiryReason(now, cfg); reason != "" {
+			rm.RemoveRoom(room.Code, reason)
+		}
+	}
+}
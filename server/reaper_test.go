@@ -0,0 +1,111 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// newReaperRoom is a test room that can be shut down
+func newReaperRoom(t *testing.T, code string) *Room {
+	t.Helper()
+	r := newTestRoom(t)
+	r.Code = code
+	r.done = make(chan struct{})
+	return r
+}
+
+func TestSweep(t *testing.T) {
+	cfg := ReaperConfig{
+		Interval:    time.Second,
+		IdleTimeout: 10 * time.Minute,
+		EndedGrace:  30 * time.Minute,
+		MaxLifetime: 6 * time.Hour,
+	}
+	now := time.Now()
+
+	tests := []struct {
+		name       string
+		created    time.Duration // Age of the room
+		emptyFor   time.Duration // Zero while occupied
+		endedFor   time.Duration // Zero while in play
+		wantReason string
+	}{
+		{"occupied", time.Hour, 0, 0, ""},
+		{"briefly empty", time.Hour, 9 * time.Minute, 0, ""},
+		{"idle", time.Hour, 11 * time.Minute, 0, "Room was idle"},
+		{"recently ended", time.Hour, 0, 29 * time.Minute, ""},
+		{"ended", time.Hour, 0, 31 * time.Minute, "Game has ended"},
+		{"expired", 7 * time.Hour, 0, 0, "Room reached its maximum lifetime"},
+		{"expired and idle", 7 * time.Hour, time.Hour, 0, "Room reached its maximum lifetime"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newReaperRoom(t, "TEST")
+			r.Created = now.Add(-tt.created)
+			if tt.emptyFor > 0 {
+				r.emptySince = now.Add(-tt.emptyFor)
+			}
+			if tt.endedFor > 0 {
+				r.endedAt = now.Add(-tt.endedFor)
+			}
+			if got := r.expiryReason(now, cfg); got != tt.wantReason {
+				t.Errorf("reason %q, want %q", got, tt.wantReason)
+			}
+
+			rm := &RoomManager{rooms: map[string]*Room{r.Code: r}}
+			rm.sweep(now, cfg)
+
+			_, kept := rm.GetRoom(r.Code)
+			if kept != (tt.wantReason == "") {
+				t.Errorf("room kept %v, want %v", kept, tt.wantReason == "")
+			}
+			select {
+			case <-r.done:
+				if kept {
+					t.Error("kept room was shut down")
+				}
+			default:
+				if !kept {
+					t.Error("removed room wasn't shut down")
+				}
+			}
+		})
+	}
+}
+
+func TestReapStops(t *testing.T) {
+	cfg := ReaperConfig{
+		Interval:    5 * time.Millisecond,
+		IdleTimeout: time.Millisecond,
+		EndedGrace:  time.Hour,
+		MaxLifetime: time.Hour,
+	}
+	idle := newReaperRoom(t, "IDLE")
+	idle.emptySince = time.Now().Add(-time.Second)
+	busy := newReaperRoom(t, "BUSY")
+	rm := &RoomManager{rooms: map[string]*Room{idle.Code: idle, busy.Code: busy}}
+
+	stop := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		rm.reap(cfg, stop)
+		close(exited)
+	}()
+
+	select {
+	case <-idle.done:
+	case <-time.After(time.Second):
+		t.Fatal("idle room was never reaped")
+	}
+	if _, exists := rm.GetRoom(busy.Code); !exists {
+		t.Error("occupied room was reaped")
+	}
+
+	close(stop)
+	select {
+	case <-exited:
+	case <-time.After(time.Second):
+		t.Fatal("reaper didn't stop")
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"log"
+	"sort"
+
+	"github.com/AmeenAhmed/hackathon/game"
+)
+
+// Standing is one player's line in a scoreboard
+type Standing struct {
+	PlayerID            string `json:"playerId"`
+	Name                string `json:"name"`
+	Score               int    `json:"score"`      // This round
+	TotalScore          int    `json:"totalScore"` // All rounds so far
+	Kills               int    `json:"kills"`
+	TotalKills          int    `json:"totalKills"`
+	CorrectAnswers      int    `json:"correctAnswers"`
+	TotalCorrectAnswers int    `json:"totalCorrectAnswers"`
+}
+
+// RoundResult is the final scoreboard of a finished round
+type RoundResult struct {
+	Round     int        `json:"round"`
+	Standings []Standing `json:"standings"`
+}
+
+// roundSummary is everything gameEnded reports about the match so far
+type roundSummary struct {
+	Round            int           `json:"round"`
+	RoundStandings   []Standing    `json:"roundStandings"`
+	OverallStandings []Standing    `json:"overallStandings"`
+	Rounds           []RoundResult `json:"rounds"`
+}
+
+// finishRound folds the round's stats into each player's cumulative totals
+// and records the round's standings. Called with the write lock held.
+func (r *Room) finishRound() {
+	for id, player := range r.GameState.Players {
+		player.TotalKills += player.Kills
+		player.TotalCorrectAnswers += player.CorrectAnswers
+		player.TotalQuestionsAttempted += player.QuestionsAttempted
+		r.GameState.TotalScore[id] += r.GameState.Score[id]
+	}
+
+	r.Rounds = append(r.Rounds, RoundResult{
+		Round:     r.GameState.Round,
+		Standings: r.standings(false),
+	})
+}
+
+// standings builds the scoreboard, sorted by this round's score or by the
+// cumulative score. Called with the lock held.
+func (r *Room) standings(overall bool) []Standing {
+	standings := make([]Standing, 0, len(r.GameState.Players))
+	for id, player := range r.GameState.Players {
+		standings = append(standings, Standing{
+			PlayerID:            id,
+			Name:                player.Name,
+			Score:               r.GameState.Score[id],
+			TotalScore:          r.GameState.TotalScore[id],
+			Kills:               player.Kills,
+			TotalKills:          player.TotalKills,
+			CorrectAnswers:      player.CorrectAnswers,
+			TotalCorrectAnswers: player.TotalCorrectAnswers,
+		})
+	}
+
+	sort.Slice(standings, func(i, j int) bool {
+		a, b := standings[i], standings[j]
+		if overall && a.TotalScore != b.TotalScore {
+			return a.TotalScore > b.TotalScore
+		}
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return a.Name < b.Name
+	})
+	return standings
+}
+
+// summary snapshots the round and overall standings. Called with the lock held.
+func (r *Room) summary() roundSummary {
+	rounds := make([]RoundResult, len(r.Rounds))
+	copy(rounds, r.Rounds)
+
+	return roundSummary{
+		Round:            r.GameState.Round,
+		RoundStandings:   r.standings(false),
+		OverallStandings: r.standings(true),
+		Rounds:           rounds,
+	}
+}
+
+// regenerateMap swaps in a freshly generated map between rounds
+func (r *Room) regenerateMap() {
+	// Generation is slow, so do it before taking the lock
+	mapData := game.Generate(r.Settings.Map, game.NewSeed())
+	collision := game.NewCollisionMap(&mapData)
+	spawns := game.NewSpawnIndex(&mapData, collision)
+	enc := newEncodedMap(&mapData)
+
+	r.mutex.Lock()
+	r.MapData = mapData
+	r.mapCache = enc
+	r.collision = collision
+	r.spawns = spawns
+	r.mutex.Unlock()
+
+	log.Printf("Room %s generated a new map (seed %d) with %d objects and %d spawnable tiles",
+		r.Code, mapData.Seed, len(mapData.MapObjects), spawns.Len())
+
+	r.broadcastMapChanged(enc)
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+)
+
+// sessionTokenBytes is the amount of randomness in a reconnect token
+const sessionTokenBytes = 32
+
+// newSessionToken returns an unguessable token a player presents to rejoin
+func newSessionToken() (string, error) {
+	b := make([]byte, sessionTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generating session token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// issueSession creates a new reconnect token for playerID in this room,
+// replacing any previous one
+func (r *Room) issueSession(playerID string) (string, error) {
+	token, err := newSessionToken()
+	if err != nil {
+		return "", err
+	}
+
+	r.mutex.Lock()
+	r.sessions[playerID] = token
+	r.mutex.Unlock()
+	return token, nil
+}
+
+// checkSession reports whether token is playerID's reconnect token for this room
+func (r *Room) checkSession(playerID, token string) bool {
+	r.mutex.RLock()
+	expected, exists := r.sessions[playerID]
+	r.mutex.RUnlock()
+
+	if !exists || token == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(token)) == 1
+}
+
+// issueDashboardSession creates a new reconnect token for the room's
+// dashboard, replacing any previous one
+func (r *Room) issueDashboardSession() (string, error) {
+	token, err := newSessionToken()
+	if err != nil {
+		return "", err
+	}
+
+	r.mutex.Lock()
+	r.dashToken = token
+	r.mutex.Unlock()
+	return token, nil
+}
+
+// checkDashboardSession reports whether token is the dashboard's reconnect token
+func (r *Room) checkDashboardSession(token string) bool {
+	r.mutex.RLock()
+	expected := r.dashToken
+	r.mutex.RUnlock()
+
+	if expected == "" || token == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(token)) == 1
+}
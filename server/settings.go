@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/AmeenAhmed/hackathon/game"
+)
+
+// RoomSettings are the per-room match rules chosen by the dashboard on createRoom.
+// Durations are in seconds on the wire.
+type RoomSettings struct {
+	MatchDuration          int     `json:"matchDuration"`          // Seconds
+	TickRate               int     `json:"tickRate"`               // Hz
+	MaxPlayers             int     `json:"maxPlayers"`             // Connected players
+	PointsPerCorrectAnswer int     `json:"pointsPerCorrectAnswer"` // Score per correct quiz answer
+	PointsPerKill          int     `json:"pointsPerKill"`          // Score per kill
+	RespawnDelay           float64 `json:"respawnDelay"`           // Seconds dead before respawn is allowed
+	SpawnProtection        float64 `json:"spawnProtection"`        // Seconds of protection after respawn
+	FriendlyFire           bool    `json:"friendlyFire"`           // Whether bullets damage other players
+	RejoinWindow           float64 `json:"rejoinWindow"`           // Seconds a disconnected player is kept for rejoin
+	ViewRadius             float64 `json:"viewRadius"`             // Pixels around a player they receive updates for, 0 for everything
+	MapSeed                *int64  `json:"mapSeed,omitempty"`      // Seed for the first map, random if unset
+
+	// Map generation starts from MapPreset ("small", "medium" or "full",
+	// picked from maxPlayers if unset) with any fields in Map overriding it
+	MapPreset string               `json:"mapPreset"`
+	Map       game.GeneratorConfig `json:"map"`
+}
+
+// DefaultSettings returns the settings rooms used before they were configurable
+func DefaultSettings() RoomSettings {
+	return RoomSettings{
+		MatchDuration:          300, // 5 minutes
+		TickRate:               60,  // 60Hz for smoother updates
+		MaxPlayers:             100,
+		PointsPerCorrectAnswer: 3,
+		PointsPerKill:          1,
+		RespawnDelay:           0,
+		SpawnProtection:        3,
+		FriendlyFire:           true,
+		RejoinWindow:           120,
+		ViewRadius:             800, // Covers the screen at the client's widest zoom
+		MapPreset:              "full",
+		Map:                    game.FullMap,
+	}
+}
+
+// parseSettings reads a settings object on top of the defaults,
+// so the dashboard only needs to send the fields it wants to change
+func parseSettings(raw json.RawMessage) (RoomSettings, error) {
+	settings := DefaultSettings()
+	settings.MapPreset = ""
+
+	var overrides struct {
+		Map json.RawMessage `json:"map"`
+	}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &settings); err != nil {
+			return settings, fmt.Errorf("invalid settings: %w", err)
+		}
+		json.Unmarshal(raw, &overrides)
+	}
+
+	// Generator fields sent in "map" apply on top of the preset
+	if settings.MapPreset == "" {
+		settings.MapPreset = game.PresetFor(settings.MaxPlayers)
+	}
+	preset, exists := game.Preset(settings.MapPreset)
+	if !exists {
+		return settings, fmt.Errorf("mapPreset must be one of %s", strings.Join(game.PresetNames(), ", "))
+	}
+	settings.Map = preset
+	if len(overrides.Map) > 0 {
+		if err := json.Unmarshal(overrides.Map, &settings.Map); err != nil {
+			return settings, fmt.Errorf("invalid map settings: %w", err)
+		}
+	}
+
+	return settings, settings.Validate()
+}
+
+// Validate checks every setting is within a sensible range
+func (s RoomSettings) Validate() error {
+	switch {
+	case s.MatchDuration < 30 || s.MatchDuration > 3600:
+		return fmt.Errorf("matchDuration must be between 30 and 3600 seconds")
+	case s.TickRate < 10 || s.TickRate > 120:
+		return fmt.Errorf("tickRate must be between 10 and 120 Hz")
+	case s.MaxPlayers < 1 || s.MaxPlayers > 200:
+		return fmt.Errorf("maxPlayers must be between 1 and 200")
+	case s.PointsPerCorrectAnswer < 0 || s.PointsPerCorrectAnswer > 100:
+		return fmt.Errorf("pointsPerCorrectAnswer must be between 0 and 100")
+	case s.PointsPerKill < 0 || s.PointsPerKill > 100:
+		return fmt.Errorf("pointsPerKill must be between 0 and 100")
+	case s.RespawnDelay < 0 || s.RespawnDelay > 60:
+		return fmt.Errorf("respawnDelay must be between 0 and 60 seconds")
+	case s.SpawnProtection < 0 || s.SpawnProtection > 30:
+		return fmt.Errorf("spawnProtection must be between 0 and 30 seconds")
+	case s.RejoinWindow < 0 || s.RejoinWindow > 3600:
+		return fmt.Errorf("rejoinWindow must be between 0 and 3600 seconds")
+	case s.ViewRadius < 0 || s.ViewRadius > 10000:
+		return fmt.Errorf("viewRadius must be between 0 and 10000 pixels")
+	case s.MapSeed != nil && (*s.MapSeed < 0 || *s.MapSeed > game.MaxSeed):
+		return fmt.Errorf("mapSeed must be between 0 and %d", int64(game.MaxSeed))
+	}
+	if err := s.Map.Validate(); err != nil {
+		return fmt.Errorf("invalid map settings: %w", err)
+	}
+	return nil
+}
+
+// TickInterval returns the time between room ticks
+func (s RoomSettings) TickInterval() time.Duration {
+	return time.Second / time.Duration(s.TickRate)
+}
+
+// RespawnDelayDuration returns the respawn delay as a time.Duration
+func (s RoomSettings) RespawnDelayDuration() time.Duration {
+	return time.Duration(s.RespawnDelay * float64(time.Second))
+}
+
+// SpawnProtectionDuration returns the spawn protection length as a time.Duration
+func (s RoomSettings) SpawnProtectionDuration() time.Duration {
+	return time.Duration(s.SpawnProtection * float64(time.Second))
+}
+
+// RejoinWindowDuration returns how long disconnected players are kept as a time.Duration
+func (s RoomSettings) RejoinWindowDuration() time.Duration {
+	return time.Duration(s.RejoinWindow * float64(time.Second))
+}
+
+// Score computes a player's score under these settings
+func (s RoomSettings) Score(player *Player) int {
+	return player.CorrectAnswers*s.PointsPerCorrectAnswer + player.Kills*s.PointsPerKill
+}
@@ -0,0 +1,300 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/AmeenAhmed/hackathon/wire"
+)
+
+// snapshotHistory is how many past snapshots are kept as delta baselines.
+// A client whose last ack is older than this gets a keyframe instead.
+const snapshotHistory = 128
+
+// snapshot is a copy of the game state as broadcast on one tick.
+// It marshals the same way as GameState.
+type snapshot struct {
+	seq        uint64
+	Players    map[string]Player `json:"players"`
+	GamePhase  GamePhase         `json:"gamePhase"`
+	Timer      int               `json:"timer"`
+	Score      map[string]int    `json:"score"`
+	Round      int               `json:"round"`
+	TotalScore map[string]int    `json:"totalScore"`
+}
+
+// stateDelta is what changed between a client's acked baseline and the
+// current snapshot. Players holds only the changed fields of each player,
+// or every field for players the baseline didn't have.
+type stateDelta struct {
+	Players    map[string]map[string]interface{} `json:"players,omitempty"`
+	Removed    []string                          `json:"removed,omitempty"`
+	GamePhase  GamePhase                         `json:"gamePhase,omitempty"`
+	Timer      *int                              `json:"timer,omitempty"`
+	Round      *int                              `json:"round,omitempty"`
+	Score      *map[string]int                   `json:"score,omitempty"`
+	TotalScore *map[string]int                   `json:"totalScore,omitempty"`
+}
+
+// takeSnapshot copies the current game state under the next sequence number.
+// Called from the ticker with the read lock held - only the ticker touches
+// the snapshot ring, so nothing else writes it concurrently.
+func (r *Room) takeSnapshot() *snapshot {
+	r.snapshotSeq++
+	snap := &snapshot{
+		seq:        r.snapshotSeq,
+		Players:    make(map[string]Player, len(r.GameState.Players)),
+		GamePhase:  r.GameState.GamePhase,
+		Timer:      r.GameState.Timer,
+		Score:      make(map[string]int, len(r.GameState.Score)),
+		Round:      r.GameState.Round,
+		TotalScore: make(map[string]int, len(r.GameState.TotalScore)),
+	}
+	for id, player := range r.GameState.Players {
+		snap.Players[id] = *player
+	}
+	for id, score := range r.GameState.Score {
+		snap.Score[id] = score
+	}
+	for id, score := range r.GameState.TotalScore {
+		snap.TotalScore[id] = score
+	}
+
+	r.snapshots[snap.seq%snapshotHistory] = snap
+	return snap
+}
+
+// baseline returns the stored snapshot with the given sequence number,
+// or nil if it has already been overwritten
+func (r *Room) baseline(seq uint64) *snapshot {
+	if seq == 0 {
+		return nil
+	}
+	snap := r.snapshots[seq%snapshotHistory]
+	if snap == nil || snap.seq != seq {
+		return nil
+	}
+	return snap
+}
+
+// diff builds the delta that takes a client from base to s
+func (s *snapshot) diff(base *snapshot) stateDelta {
+	var delta stateDelta
+
+	for id, player := range s.Players {
+		old, existed := base.Players[id]
+		var fields map[string]interface{}
+		if existed {
+			fields = playerDelta(&old, &player)
+		} else {
+			fields = playerDelta(nil, &player)
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		if delta.Players == nil {
+			delta.Players = make(map[string]map[string]interface{})
+		}
+		delta.Players[id] = fields
+	}
+	for id := range base.Players {
+		if _, exists := s.Players[id]; !exists {
+			delta.Removed = append(delta.Removed, id)
+		}
+	}
+
+	if s.GamePhase != base.GamePhase {
+		delta.GamePhase = s.GamePhase
+	}
+	if s.Timer != base.Timer {
+		timer := s.Timer
+		delta.Timer = &timer
+	}
+	if s.Round != base.Round {
+		round := s.Round
+		delta.Round = &round
+	}
+	if !sameScores(s.Score, base.Score) {
+		delta.Score = &s.Score
+	}
+	if !sameScores(s.TotalScore, base.TotalScore) {
+		delta.TotalScore = &s.TotalScore
+	}
+	return delta
+}
+
+// sameScores reports whether two score maps hold the same entries
+func sameScores(a, b map[string]int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for id, score := range a {
+		if other, exists := b[id]; !exists || other != score {
+			return false
+		}
+	}
+	return true
+}
+
+// playerDelta returns the broadcast fields that differ between old and cur,
+// keyed by their JSON names. A nil old returns every field.
+// Fields the client never sees are skipped.
+func playerDelta(old, cur *Player) map[string]interface{} {
+	all := old == nil
+	if all {
+		old = &Player{}
+	}
+
+	fields := make(map[string]interface{})
+	set := func(name string, changed bool, value interface{}) {
+		if all || changed {
+			fields[name] = value
+		}
+	}
+
+	set("id", old.ID != cur.ID, cur.ID)
+	set("name", old.Name != cur.Name, cur.Name)
+	set("color", old.Color != cur.Color, cur.Color)
+	set("x", old.X != cur.X, cur.X)
+	set("y", old.Y != cur.Y, cur.Y)
+	set("animation", old.Animation != cur.Animation, cur.Animation)
+	set("direction", old.Direction != cur.Direction, cur.Direction)
+	set("gunRotation", old.GunRotation != cur.GunRotation, cur.GunRotation)
+	set("gunFlipped", old.GunFlipped != cur.GunFlipped, cur.GunFlipped)
+	set("currentGun", old.CurrentGun != cur.CurrentGun, cur.CurrentGun)
+	set("isProtected", old.IsProtected != cur.IsProtected, cur.IsProtected)
+	set("disconnected", old.Disconnected != cur.Disconnected, cur.Disconnected)
+	set("correctAnswers", old.CorrectAnswers != cur.CorrectAnswers, cur.CorrectAnswers)
+	set("questionsAttempted", old.QuestionsAttempted != cur.QuestionsAttempted, cur.QuestionsAttempted)
+	set("kills", old.Kills != cur.Kills, cur.Kills)
+	set("totalCorrectAnswers", old.TotalCorrectAnswers != cur.TotalCorrectAnswers, cur.TotalCorrectAnswers)
+	set("totalQuestionsAttempted", old.TotalQuestionsAttempted != cur.TotalQuestionsAttempted, cur.TotalQuestionsAttempted)
+	set("totalKills", old.TotalKills != cur.TotalKills, cur.TotalKills)
+	set("health", old.Health != cur.Health, cur.Health)
+	set("maxHealth", old.MaxHealth != cur.MaxHealth, cur.MaxHealth)
+	return fields
+}
+
+// stateMessage returns the gameUpdate keyframe or gameDelta for a client,
+// reusing messages already built this tick for the same baseline
+func (r *Room) stateMessage(client *Client, snap *snapshot, grid *spatialGrid, timestamp int64, built map[uint64]*wire.Message) *wire.Message {
+	base := r.baseline(client.ackedSnapshot.Load())
+
+	// Players only see who's around them. Remember what each client was
+	// sent so later deltas are taken against that same view.
+	view := snap
+	ids := r.viewFor(client, snap, grid)
+	if ids != nil {
+		view = snap.filtered(ids)
+		client.visible[snap.seq%snapshotHistory] = visibleSet{seq: snap.seq, ids: ids}
+		if base != nil {
+			if seen := client.visibleAt(base.seq); seen != nil {
+				base = base.filtered(seen)
+			} else {
+				base = nil
+			}
+		}
+	}
+
+	// Keyframes go out when the client has no usable baseline and
+	// periodically so a client can recover from any missed state
+	baseSeq := uint64(0)
+	if base != nil && snap.seq-client.lastKeyframe < uint64(r.Settings.TickRate) {
+		baseSeq = base.seq
+	}
+	if baseSeq == 0 {
+		client.lastKeyframe = snap.seq
+	}
+
+	// Filtered views are unique to the client and can't be shared
+	if data, exists := built[baseSeq]; exists && ids == nil {
+		return data
+	}
+
+	var message interface{}
+	if baseSeq == 0 {
+		message = struct {
+			Type      string    `json:"type"`
+			GameState *snapshot `json:"gameState"`
+			Snapshot  uint64    `json:"snapshot"`
+			Keyframe  bool      `json:"keyframe"`
+			Timestamp int64     `json:"timestamp"`
+		}{
+			Type:      "gameUpdate",
+			GameState: view,
+			Snapshot:  snap.seq,
+			Keyframe:  true,
+			Timestamp: timestamp,
+		}
+	} else {
+		message = struct {
+			Type      string `json:"type"`
+			Snapshot  uint64 `json:"snapshot"`
+			Baseline  uint64 `json:"baseline"`
+			Timestamp int64  `json:"timestamp"`
+			stateDelta
+		}{
+			Type:       "gameDelta",
+			Snapshot:   snap.seq,
+			Baseline:   baseSeq,
+			Timestamp:  timestamp,
+			stateDelta: view.diff(base),
+		}
+	}
+
+	encoded, err := json.Marshal(message)
+	if err != nil {
+		log.Printf("Error marshaling game state: %v", err)
+		return nil
+	}
+	data := wire.NewMessage(encoded)
+	if ids == nil {
+		built[baseSeq] = data
+	}
+	return data
+}
+
+// broadcastGameState sends every client the state of this tick, as a delta
+// against the last snapshot it acknowledged where possible
+func (r *Room) broadcastGameState() {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	// Don't send MapData in regular updates - only GameState
+	// MapData is static and only needs to be sent once on join/rejoin
+	snap := r.takeSnapshot()
+	grid := newSpatialGrid(snap.Players)
+	r.grid.Store(grid)
+	timestamp := time.Now().UnixMilli()
+	built := make(map[uint64]*wire.Message)
+
+	send := func(client *Client) {
+		data := r.stateMessage(client, snap, grid, timestamp, built)
+		if data == nil {
+			return
+		}
+		select {
+		case client.Send <- data:
+		default:
+			// Client's send channel is full, skip
+		}
+	}
+
+	if r.Dashboard != nil {
+		send(r.Dashboard)
+	}
+	for _, client := range r.Players {
+		send(client)
+	}
+}
+
+// ackSnapshot records the newest snapshot the client has applied
+func (c *Client) ackSnapshot(seq uint64) {
+	for {
+		current := c.ackedSnapshot.Load()
+		if seq <= current || c.ackedSnapshot.CompareAndSwap(current, seq) {
+			return
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"encoding/json"
+	"sort"
+	"testing"
+)
+
+func TestSnapshotDiff(t *testing.T) {
+	alice := Player{ID: "a", Name: "Alice", X: 10, Y: 20, Health: 100, MaxHealth: 100}
+	moved := alice
+	moved.X, moved.Animation = 15, "run"
+	bob := Player{ID: "b", Name: "Bob", Health: 100, MaxHealth: 100}
+
+	tests := []struct {
+		name string
+		base snapshot
+		cur  snapshot
+		want string // JSON of the expected delta
+	}{
+		{
+			name: "nothing changed",
+			base: snapshot{Players: map[string]Player{"a": alice}, GamePhase: PhasePlaying, Timer: 60},
+			cur:  snapshot{Players: map[string]Player{"a": alice}, GamePhase: PhasePlaying, Timer: 60},
+			want: `{}`,
+		},
+		{
+			name: "changed fields only",
+			base: snapshot{Players: map[string]Player{"a": alice}},
+			cur:  snapshot{Players: map[string]Player{"a": moved}},
+			want: `{"players":{"a":{"animation":"run","x":15}}}`,
+		},
+		{
+			name: "removed player",
+			base: snapshot{Players: map[string]Player{"a": alice, "b": bob}},
+			cur:  snapshot{Players: map[string]Player{"a": alice}},
+			want: `{"removed":["b"]}`,
+		},
+		{
+			name: "phase, timer and round",
+			base: snapshot{GamePhase: PhaseCountdown, Timer: 300, Round: 0},
+			cur:  snapshot{GamePhase: PhasePlaying, Timer: 299, Round: 1},
+			want: `{"gamePhase":"playing","timer":299,"round":1}`,
+		},
+		{
+			name: "timer back to zero",
+			base: snapshot{Timer: 1},
+			cur:  snapshot{Timer: 0},
+			want: `{"timer":0}`,
+		},
+		{
+			name: "scores sent whole when any changed",
+			base: snapshot{Score: map[string]int{"a": 1, "b": 2}, TotalScore: map[string]int{"a": 5}},
+			cur:  snapshot{Score: map[string]int{"a": 1, "b": 3}, TotalScore: map[string]int{"a": 5}},
+			want: `{"score":{"a":1,"b":3}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.cur.diff(&tt.base))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// A player the baseline didn't have must arrive with every field the
+// client sees in a keyframe, or the client ends up with a partial player
+func TestPlayerDeltaNewPlayerHasAllFields(t *testing.T) {
+	player := Player{ID: "a", Name: "Alice", Health: 100, MaxHealth: 100}
+
+	data, err := json.Marshal(player)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keyframe map[string]interface{}
+	if err := json.Unmarshal(data, &keyframe); err != nil {
+		t.Fatal(err)
+	}
+
+	fields := playerDelta(nil, &player)
+	if got, want := sortedKeys(fields), sortedKeys(keyframe); !equalStrings(got, want) {
+		t.Errorf("delta fields %v, keyframe fields %v", got, want)
+	}
+}
+
+func TestBaseline(t *testing.T) {
+	r := newTestRoom(t)
+	first := r.takeSnapshot()
+
+	tests := []struct {
+		name    string
+		advance int // Snapshots taken after the first, cumulative across cases
+		seq     uint64
+		found   bool
+	}{
+		{"zero is never a baseline", 0, 0, false},
+		{"latest", 0, first.seq, true},
+		{"not taken yet", 0, first.seq + 1, false},
+		{"still in history", snapshotHistory - 1, first.seq, true},
+		{"overwritten", snapshotHistory, first.seq, false},
+	}
+
+	taken := 0
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for ; taken < tt.advance; taken++ {
+				r.takeSnapshot()
+			}
+			if got := r.baseline(tt.seq); (got != nil) != tt.found {
+				t.Errorf("baseline(%d) found %v, want %v", tt.seq, got != nil, tt.found)
+			}
+		})
+	}
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"log"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/AmeenAhmed/hackathon/game"
+)
+
+// Spawn selection tuning
+const (
+	RecentDeathWindow = 10 * time.Second // How long a death site is avoided
+	SafeSpawnDistance = 480.0            // Pixels; zones this far from every threat are equally good
+)
+
+// deathSite is where a player was recently killed
+type deathSite struct {
+	x, y float64
+	at   time.Time
+}
+
+// recordDeath remembers a kill so spawns avoid it for a while.
+// Called with the write lock held.
+func (r *Room) recordDeath(x, y float64) {
+	r.pruneDeaths(time.Now())
+	r.recentDeaths = append(r.recentDeaths, deathSite{x: x, y: y, at: time.Now()})
+}
+
+// pruneDeaths drops death sites older than RecentDeathWindow.
+// Called with the write lock held.
+func (r *Room) pruneDeaths(now time.Time) {
+	kept := r.recentDeaths[:0]
+	for _, death := range r.recentDeaths {
+		if now.Sub(death.at) < RecentDeathWindow {
+			kept = append(kept, death)
+		}
+	}
+	r.recentDeaths = kept
+}
+
+// spawnPoint picks where a player spawns, away from living enemies and
+// recent deaths
+func (r *Room) spawnPoint(playerID string) (float64, float64) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.chooseSpawn(playerID)
+}
+
+// chooseSpawn is spawnPoint for callers that already hold the lock.
+// Zones are tried safest first, at random among equally safe ones so
+// players spread out, taking only tiles clear of every threat. Only when
+// no tile anywhere is clear does the zone furthest from the nearest
+// threat win.
+func (r *Room) chooseSpawn(playerID string) (float64, float64) {
+	threats := r.spawnThreats(playerID)
+	safe := game.FarFrom(threats, SafeSpawnDistance)
+
+	zones := r.MapData.SpawnZones
+	safety := make([]float64, len(zones))
+	for i, zone := range zones {
+		x, y := game.TileCenter(zone.X, zone.Y)
+		safety[i] = SafeSpawnDistance
+		for _, threat := range threats {
+			safety[i] = math.Min(safety[i], math.Hypot(threat[0]-x, threat[1]-y))
+		}
+	}
+
+	order := rand.Perm(len(zones))
+	sort.SliceStable(order, func(a, b int) bool {
+		return safety[order[a]] > safety[order[b]]
+	})
+	for _, i := range order {
+		if x, y, ok := r.spawns.PickInZone(i, safe); ok {
+			return x, y
+		}
+	}
+
+	// No safe zone tiles - anywhere clear of threats, else the safest
+	// zone, else anywhere at all
+	if x, y, ok := r.spawns.Pick(safe); ok {
+		return x, y
+	}
+	for _, i := range order {
+		if x, y, ok := r.spawns.PickInZone(i, nil); ok {
+			return x, y
+		}
+	}
+	if x, y, ok := r.spawns.Pick(nil); ok {
+		return x, y
+	}
+	log.Printf("Room %s has no spawnable tiles, spawning at the center", r.Code)
+	return float64(r.MapData.Width / 2), float64(r.MapData.Height / 2)
+}
+
+// spawnThreats lists the positions a player shouldn't spawn near: other
+// living players and recent death sites. Called with the lock held.
+func (r *Room) spawnThreats(playerID string) [][2]float64 {
+	var threats [][2]float64
+	for id, player := range r.GameState.Players {
+		if id == playerID || player.Health <= 0 || player.Disconnected {
+			continue
+		}
+		threats = append(threats, [2]float64{player.X, player.Y})
+	}
+
+	now := time.Now()
+	for _, death := range r.recentDeaths {
+		if now.Sub(death.at) < RecentDeathWindow {
+			threats = append(threats, [2]float64{death.x, death.y})
+		}
+	}
+	return threats
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"math"
+	"testing"
+
+	"github.com/AmeenAhmed/hackathon/game"
+)
+
+// Spawn zones at either end of spawnStripMap, in tiles
+var (
+	westZone = game.SpawnZone{X: 5, Y: 5, Radius: 2}
+	eastZone = game.SpawnZone{X: 75, Y: 5, Radius: 2}
+)
+
+// spawnStripMap is an 80x10 tile strip of floor, much wider than
+// SafeSpawnDistance, with the given spawn zones
+func spawnStripMap(terrain int, zones ...game.SpawnZone) game.MapData {
+	mapData := game.MapData{Width: 80 * game.TileSize, Height: 10 * game.TileSize, SpawnZones: zones}
+	for y := 0; y < 10; y++ {
+		row := make([]int, 80)
+		for x := range row {
+			row[x] = terrain
+		}
+		mapData.Terrain = append(mapData.Terrain, row)
+	}
+	return mapData
+}
+
+// zoneCenter returns the pixel at the center of a zone
+func zoneCenter(zone game.SpawnZone) [2]float64 {
+	x, y := game.TileCenter(zone.X, zone.Y)
+	return [2]float64{x, y}
+}
+
+// inZone reports whether a pixel position is on one of the zone's tiles
+func inZone(x, y float64, zone game.SpawnZone) bool {
+	center := zoneCenter(zone)
+	return math.Hypot(x-center[0], y-center[1]) <= float64(zone.Radius*game.TileSize)
+}
+
+func TestChooseSpawn(t *testing.T) {
+	_, midY := game.TileCenter(0, 5)
+	bothZones := [][2]float64{zoneCenter(westZone), zoneCenter(eastZone)}
+
+	tests := []struct {
+		name    string
+		mapData game.MapData
+		threats [][2]float64 // Positions of other living players
+		check   func(x, y float64) bool
+	}{
+		{
+			"zone away from threats",
+			spawnStripMap(game.TileFloor, westZone, eastZone),
+			[][2]float64{zoneCenter(westZone)},
+			func(x, y float64) bool { return inZone(x, y, eastZone) },
+		},
+		{
+			"every zone near a threat - safe tile elsewhere",
+			spawnStripMap(game.TileFloor, westZone, eastZone),
+			bothZones,
+			func(x, y float64) bool {
+				return !inZone(x, y, westZone) && !inZone(x, y, eastZone) && game.FarFrom(bothZones, SafeSpawnDistance)(x, y)
+			},
+		},
+		{
+			"no spawnable zone tiles",
+			spawnStripMap(game.TileFloor, game.SpawnZone{X: -10, Y: -10, Radius: 2}),
+			[][2]float64{{100, midY}},
+			func(x, y float64) bool { return math.Hypot(x-100, y-midY) >= SafeSpawnDistance },
+		},
+		{
+			// Threats cover the whole strip; the east zone is furthest from them
+			"every tile near a threat",
+			spawnStripMap(game.TileFloor, westZone, eastZone),
+			[][2]float64{{250, midY}, {960, midY}},
+			func(x, y float64) bool { return inZone(x, y, eastZone) },
+		},
+		{
+			"every tile near a threat and no zones",
+			spawnStripMap(game.TileFloor),
+			[][2]float64{{250, midY}, {960, midY}},
+			func(x, y float64) bool { return x > 0 && x < 80*game.TileSize && y > 0 && y < 10*game.TileSize },
+		},
+		{
+			"nothing spawnable",
+			spawnStripMap(game.TileOutside, westZone, eastZone),
+			nil,
+			func(x, y float64) bool { return x == 40*game.TileSize && y == 5*game.TileSize },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRoom(t)
+			r.MapData = tt.mapData
+			r.collision = game.NewCollisionMap(&r.MapData)
+			r.spawns = game.NewSpawnIndex(&r.MapData, r.collision)
+			for i, threat := range tt.threats {
+				id := string(rune('a' + i))
+				r.GameState.Players[id] = &Player{ID: id, X: threat[0], Y: threat[1], Health: 100}
+			}
+
+			// Spawns are random - every pick has to pass
+			for i := 0; i < 20; i++ {
+				x, y := r.chooseSpawn("me")
+				if !tt.check(x, y) {
+					t.Fatalf("spawned at (%v, %v)", x, y)
+				}
+			}
+		})
+	}
+}
+
+func TestSpawnThreats(t *testing.T) {
+	r := newTestRoom(t)
+	r.GameState.Players["me"] = &Player{ID: "me", X: 1, Y: 1, Health: 100}
+	r.GameState.Players["alive"] = &Player{ID: "alive", X: 2, Y: 2, Health: 100}
+	r.GameState.Players["dead"] = &Player{ID: "dead", X: 3, Y: 3, Health: 0}
+	r.GameState.Players["gone"] = &Player{ID: "gone", X: 4, Y: 4, Health: 100, Disconnected: true}
+	r.recordDeath(5, 5)
+
+	threats := r.spawnThreats("me")
+	want := map[[2]float64]bool{{2, 2}: true, {5, 5}: true}
+	if len(threats) != len(want) {
+		t.Fatalf("threats %v, want %v", threats, want)
+	}
+	for _, threat := range threats {
+		if !want[threat] {
+			t.Errorf("unexpected threat %v", threat)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+// Package wire handles the encodings a client can choose for its websocket
+// messages. Handlers always work with JSON; the chosen format is applied
+// at the connection's edge.
+package wire
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// Format is a websocket message encoding
+type Format string
+
+const (
+	FormatJSON    Format = "json"    // Text frames, the default
+	FormatMsgPack Format = "msgpack" // Binary MessagePack frames
+)
+
+// Subprotocols lists the formats offered during the websocket handshake,
+// in order of preference
+var Subprotocols = []string{string(FormatMsgPack), string(FormatJSON)}
+
+// ParseFormat validates a format name. An empty name means JSON.
+func ParseFormat(name string) (Format, error) {
+	switch Format(name) {
+	case "", FormatJSON:
+		return FormatJSON, nil
+	case FormatMsgPack:
+		return FormatMsgPack, nil
+	}
+	return "", fmt.Errorf("unsupported encoding %q", name)
+}
+
+// Message is an outbound JSON message. It keeps its MessagePack form once
+// built, so a broadcast is converted once however many clients receive it.
+type Message struct {
+	JSON []byte
+
+	once    sync.Once
+	msgpack []byte
+	err     error
+}
+
+// NewMessage wraps a JSON message for sending
+func NewMessage(data []byte) *Message {
+	return &Message{JSON: data}
+}
+
+// Encode returns the message as a frame in format f. It is safe to call
+// from the write loops of every client the message was sent to.
+func (m *Message) Encode(f Format) (int, []byte, error) {
+	if f == FormatMsgPack {
+		m.once.Do(func() {
+			m.msgpack, m.err = JSONToMsgPack(m.JSON)
+		})
+		return websocket.BinaryMessage, m.msgpack, m.err
+	}
+	return websocket.TextMessage, m.JSON, nil
+}
+
+// Decode converts an inbound frame into JSON. Binary frames are always
+// MessagePack and text frames always JSON, whatever was negotiated.
+func Decode(messageType int, data []byte) ([]byte, error) {
+	if messageType == websocket.BinaryMessage {
+		return MsgPackToJSON(data)
+	}
+	return data, nil
+}
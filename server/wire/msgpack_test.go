@@ -0,0 +1,142 @@
+package wire
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestJSONToMsgPack(t *testing.T) {
+	tests := []struct {
+		json string
+		want string // Hex
+	}{
+		{`null`, "c0"},
+		{`true`, "c3"},
+		{`false`, "c2"},
+		{`0`, "00"},
+		{`127`, "7f"},
+		{`-1`, "ff"},
+		{`-32`, "e0"},
+		{`-33`, "d0df"},
+		{`200`, "d100c8"},
+		{`70000`, "d200011170"},
+		{`5000000000`, "d3000000012a05f200"},
+		{`1.5`, "ca3fc00000"},
+		{`0.1`, "cb3fb999999999999a"},
+		{`"a"`, "a161"},
+		{`[]`, "90"},
+		{`[1,"x"]`, "9201a178"},
+		{`{}`, "80"},
+		{`{"b":1,"a":2}`, "82a16102a16201"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.json, func(t *testing.T) {
+			got, err := JSONToMsgPack([]byte(tt.json))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if hex.EncodeToString(got) != tt.want {
+				t.Errorf("got %x, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMsgPackRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+	}{
+		{"message", `{"type":"gameDelta","snapshot":42,"players":{"a":{"x":10.25,"y":-3,"isProtected":true}},"removed":["b"]}`},
+		{"str8", fmt.Sprintf("%q", strings.Repeat("x", 40))},
+		{"str16", fmt.Sprintf("%q", strings.Repeat("y", 300))},
+		{"array16", "[" + strings.TrimSuffix(strings.Repeat("1,", 20), ",") + "]"},
+		{"map16", func() string {
+			fields := make([]string, 20)
+			for i := range fields {
+				fields[i] = fmt.Sprintf(`"k%d":%d`, i, i)
+			}
+			return "{" + strings.Join(fields, ",") + "}"
+		}()},
+		{"negative ints", `[-100,-1000,-100000,-10000000000]`},
+		{"unicode", `"héllo ✓"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			packed, err := JSONToMsgPack([]byte(tt.json))
+			if err != nil {
+				t.Fatal(err)
+			}
+			back, err := MsgPackToJSON(packed)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var want, got interface{}
+			if err := json.Unmarshal([]byte(tt.json), &want); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal(back, &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got %s, want %s", back, tt.json)
+			}
+		})
+	}
+}
+
+func TestMsgPackToJSONErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string // Hex
+	}{
+		{"empty", ""},
+		{"truncated string", "a361"},
+		{"truncated int", "d100"},
+		{"bogus array length", "dd7fffffff"},
+		{"trailing bytes", "c0c0"},
+		{"non-string key", "810101"},
+		{"invalid utf-8", "a1ff"},
+		{"unsupported type", "c1"},
+		{"too deep", strings.Repeat("91", maxDepth+2) + "c0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input, err := hex.DecodeString(tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, err := MsgPackToJSON(input); err == nil {
+				t.Errorf("expected an error, got %s", got)
+			}
+		})
+	}
+}
+
+func TestMessageEncode(t *testing.T) {
+	message := NewMessage([]byte(`{"type":"timerUpdate","timer":5}`))
+
+	messageType, data, err := message.Encode(FormatJSON)
+	if err != nil || messageType != websocket.TextMessage || !bytes.Equal(data, message.JSON) {
+		t.Errorf("json: got type %d %s %v", messageType, data, err)
+	}
+
+	want, _ := JSONToMsgPack(message.JSON)
+	for i := 0; i < 2; i++ {
+		messageType, data, err := message.Encode(FormatMsgPack)
+		if err != nil || messageType != websocket.BinaryMessage || !bytes.Equal(data, want) {
+			t.Errorf("msgpack call %d: got type %d %x %v", i, messageType, data, err)
+		}
+	}
+}